@@ -0,0 +1,464 @@
+package sq
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+// bulkValuesAlias is the alias of the rows of values of a bulk update.
+const bulkValuesAlias = "v"
+
+type bulkUpdateData struct {
+	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	Prefixes          []SQLizer
+	Table             string
+	KeyColumns        []string
+	SetColumns        []string
+	Rows              [][]interface{}
+	ColumnTypes       []columnType
+	WhereParts        []SQLizer
+	MaxParams         int
+	Suffixes          []SQLizer
+	Scopes            []scope
+	Unscoped          bool
+	Comments          []map[string]string
+	Commenter         func() map[string]string
+}
+
+// columnType is the SQL type the values of a column of a bulk update are cast
+// to.
+type columnType struct {
+	column string
+	typ    string
+}
+
+func (d *bulkUpdateData) ToSQL() (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = rawToSQL(d)
+	if err != nil {
+		return
+	}
+
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr += sqlComment(d.Commenter, d.Comments)
+	return
+}
+
+func (d *bulkUpdateData) toSQLPretty(indent string) (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = prettyToSQL(d, indent)
+	if err != nil {
+		return
+	}
+
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr += sqlComment(d.Commenter, d.Comments)
+	return
+}
+
+func (d *bulkUpdateData) writeSQL(w *sqlWriter) {
+	defer w.setDialect(d.Dialect)()
+
+	refs := parseTableRefs(d.Table)
+	if len(refs) != 1 {
+		w.Fail(errors.New("bulk updates must specify a single table"))
+		return
+	}
+	ref := refs[0].ref
+	if ref == bulkValuesAlias {
+		w.Fail(fmt.Errorf("bulk updates can't update a table referred to as %s", bulkValuesAlias))
+		return
+	}
+	if len(d.KeyColumns) == 0 || len(d.SetColumns) == 0 {
+		w.Fail(errors.New("bulk updates must have at least one key column and one column to set"))
+		return
+	}
+	if len(d.Rows) == 0 {
+		w.Fail(errors.New("bulk updates must have at least one row"))
+		return
+	}
+
+	maxParams := d.maxParams()
+	if maxParams <= 0 {
+		w.Fail(fmt.Errorf("bulk updates are not supported for %s", d.Dialect))
+		return
+	}
+	start := len(w.args)
+
+	if len(d.Prefixes) > 0 {
+		w.WriteClause("PREFIX", d.Prefixes, " ")
+		w.Break()
+	}
+
+	// the key columns match the rows of the table to the rows of values
+	match := make([]SQLizer, len(d.KeyColumns))
+	for i, col := range d.KeyColumns {
+		match[i] = Expr(ref + "." + col + " = " + bulkValuesAlias + "." + col)
+	}
+	sets := make([]setClause, len(d.SetColumns))
+	for i, col := range d.SetColumns {
+		sets[i] = setClause{column: col, value: Expr(bulkValuesAlias + "." + col)}
+		if d.Dialect == MySQL {
+			sets[i].column = ref + "." + col
+		}
+	}
+
+	whereParts := d.WhereParts
+	if !d.Unscoped && len(d.Scopes) > 0 {
+		preds := scopeTables(d.Table, d.Scopes)
+		whereParts = append(whereParts[:len(whereParts):len(whereParts)], preds...)
+	}
+
+	switch d.Dialect {
+	case MySQL:
+		w.WriteString("UPDATE ")
+		w.WriteString(d.Table)
+		w.Break()
+		w.WriteString("JOIN ")
+		d.writeValues(w)
+		w.WriteString(" ON ")
+		w.WriteConditions("ON", match)
+		w.Break()
+		writeSetClauses(w, sets)
+	case SQLServer:
+		w.WriteString("UPDATE ")
+		w.WriteString(ref)
+		w.Break()
+		writeSetClauses(w, sets)
+		w.Break()
+		w.WriteString("FROM ")
+		w.WriteString(d.Table)
+		w.WriteString(" JOIN ")
+		d.writeValues(w)
+		w.WriteString(" ON ")
+		w.WriteConditions("ON", match)
+	default:
+		w.WriteString("UPDATE ")
+		w.WriteString(d.Table)
+		w.Break()
+		writeSetClauses(w, sets)
+		w.Break()
+		w.WriteString("FROM ")
+		d.writeValues(w)
+		whereParts = append(match, whereParts...)
+	}
+
+	if len(whereParts) > 0 {
+		w.Break()
+		w.WriteString("WHERE ")
+		w.WriteConditions("WHERE", whereParts)
+	}
+
+	if len(d.Suffixes) > 0 {
+		w.Break()
+		w.WriteClause("SUFFIX", d.Suffixes, " ")
+	}
+
+	if n := len(w.args) - start; w.err == nil && n > maxParams {
+		w.Fail(fmt.Errorf("bulk update has %d args, more than the limit of %d; use Chunks to split its rows", n, maxParams))
+	}
+}
+
+// writeValues writes the rows of values as a derived table: a VALUES list
+// where the columns of a derived table can be named, and a UNION ALL of
+// SELECTs elsewhere.
+func (d *bulkUpdateData) writeValues(w *sqlWriter) {
+	cols := append(d.KeyColumns[:len(d.KeyColumns):len(d.KeyColumns)], d.SetColumns...)
+	types := make([]string, len(cols))
+	for _, t := range d.ColumnTypes {
+		for i, col := range cols {
+			if col == t.column {
+				types[i] = t.typ
+			}
+		}
+	}
+
+	values := d.Dialect == Postgres || d.Dialect == SQLServer
+	if values {
+		w.WriteString("(VALUES ")
+	} else {
+		w.WriteString("(SELECT ")
+	}
+	sep := w.ListSep(",")
+	if !values {
+		sep = w.ListSep(" UNION ALL ") + "SELECT "
+	}
+	for r, row := range d.Rows {
+		m := w.mark()
+		if len(row) != len(cols) {
+			w.Fail(fmt.Errorf("bulk update rows must have %d values, got %d", len(cols), len(row)))
+			w.recordPartError(m, "VALUES", r, nil)
+			continue
+		}
+		if r > 0 {
+			w.WriteString(sep)
+		}
+		if values {
+			w.WriteByte('(')
+		}
+		for i, val := range row {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			// the types of the columns of a derived table are those of its
+			// first row
+			if r == 0 && types[i] != "" {
+				w.WriteString("CAST(")
+				writeValue(w, val)
+				w.WriteString(" AS ")
+				w.WriteString(types[i])
+				w.WriteByte(')')
+			} else {
+				writeValue(w, val)
+			}
+			if !values && r == 0 {
+				w.WriteString(" AS ")
+				w.WriteString(cols[i])
+			}
+		}
+		if values {
+			w.WriteByte(')')
+		}
+		w.recordPartError(m, "VALUES", r, nil)
+	}
+	w.WriteString(") AS ")
+	w.WriteString(bulkValuesAlias)
+	if values {
+		w.WriteByte('(')
+		w.WriteString(strings.Join(cols, ", "))
+		w.WriteByte(')')
+	}
+}
+
+func writeValue(w *sqlWriter, val interface{}) {
+	if _, ok := val.(defaultExpr); ok {
+		w.Fail(errors.New("bulk update rows can't contain Default values"))
+		return
+	}
+	if vs, ok := val.(SQLizer); ok {
+		w.WriteSQL(vs)
+	} else {
+		w.WriteArg(val)
+	}
+}
+
+// maxParams returns the maximum number of args of a statement.
+func (d *bulkUpdateData) maxParams() int {
+	if d.MaxParams > 0 {
+		return d.MaxParams
+	}
+	switch d.Dialect {
+	case Postgres, MySQL:
+		return 65535
+	case SQLite:
+		return 32766
+	case SQLServer:
+		return 2100
+	default:
+		return 0
+	}
+}
+
+// Builder
+
+// BulkUpdateBuilder builds SQL UPDATE statements setting many rows to
+// different values in one statement, by joining the table to the rows of
+// values on key columns.
+//
+// Ex:
+//
+//	BulkUpdate("users", []string{"id"}, []string{"name", "age"}, [][]interface{}{
+//		{1, "moe", 13},
+//		{2, "larry", 14},
+//	})
+//	// Postgres:
+//	// UPDATE users SET name = v.name, age = v.age
+//	// FROM (VALUES (?, ?, ?),(?, ?, ?)) AS v(id, name, age) WHERE users.id = v.id
+//	// MySQL:
+//	// UPDATE users JOIN (SELECT ? AS id, ? AS name, ? AS age UNION ALL SELECT ?, ?, ?) AS v
+//	// ON users.id = v.id SET users.name = v.name, users.age = v.age
+type BulkUpdateBuilder builder.Builder
+
+func init() {
+	builder.Register(BulkUpdateBuilder{}, bulkUpdateData{})
+}
+
+// Format methods
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
+// query.
+func (b BulkUpdateBuilder) PlaceholderFormat(f PlaceholderFormat) BulkUpdateBuilder {
+	return builder.Set(b, "PlaceholderFormat", f).(BulkUpdateBuilder)
+}
+
+// Dialect sets the dialect of the query, which chooses its syntax: UPDATE ...
+// FROM (VALUES ...) on Postgres, UPDATE ... FROM a UNION ALL of SELECTs on
+// SQLite, UPDATE ... JOIN on MySQL and UPDATE ... FROM ... JOIN (VALUES ...)
+// on SQL Server.
+func (b BulkUpdateBuilder) Dialect(d Dialect) BulkUpdateBuilder {
+	return builder.Set(b, "Dialect", d).(BulkUpdateBuilder)
+}
+
+// SQL methods
+
+// ToSQL builds the query into a SQL string and bound args. It returns an error
+// if the query has more args than MaxParams; use Chunks to split its rows
+// into several queries.
+func (b BulkUpdateBuilder) ToSQL() (string, []interface{}, error) {
+	data := builder.GetStruct(b).(bulkUpdateData)
+	return data.ToSQL()
+}
+
+// ToSQLPretty is like ToSQL, but puts each clause and each row of the values
+// on its own line.
+//
+// See SelectBuilder.ToSQLPretty for more information.
+func (b BulkUpdateBuilder) ToSQLPretty(indent string) (string, []interface{}, error) {
+	data := builder.GetStruct(b).(bulkUpdateData)
+	return data.toSQLPretty(indent)
+}
+
+func (b BulkUpdateBuilder) writeSQL(w *sqlWriter) {
+	data := builder.GetStruct(b).(bulkUpdateData)
+	data.writeSQL(w)
+}
+
+// MustSQL builds the query into a SQL string and bound args.
+// It panics if there are any errors.
+func (b BulkUpdateBuilder) MustSQL() (string, []interface{}) {
+	sql, args, err := b.ToSQL()
+	if err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
+// Prefix adds an expression to the beginning of the query.
+func (b BulkUpdateBuilder) Prefix(sql string, args ...interface{}) BulkUpdateBuilder {
+	return builder.Append(b, "Prefixes", newPart(Expr(sql, args...))).(BulkUpdateBuilder)
+}
+
+// PrefixExpr adds an expression to the very beginning of the query.
+func (b BulkUpdateBuilder) PrefixExpr(expr SQLizer) BulkUpdateBuilder {
+	return builder.Append(b, "Prefixes", newPart(expr)).(BulkUpdateBuilder)
+}
+
+// Table sets the table to be updated, optionally with an alias.
+func (b BulkUpdateBuilder) Table(table string) BulkUpdateBuilder {
+	return builder.Set(b, "Table", table).(BulkUpdateBuilder)
+}
+
+// Rows sets the rows of values of the query. Each row has the values of the
+// key columns followed by those of the columns to set, which can be any
+// SQLizer.
+func (b BulkUpdateBuilder) Rows(keyColumns, setColumns []string, rows [][]interface{}) BulkUpdateBuilder {
+	b = builder.Set(b, "KeyColumns", keyColumns).(BulkUpdateBuilder)
+	b = builder.Set(b, "SetColumns", setColumns).(BulkUpdateBuilder)
+	return builder.Set(b, "Rows", rows).(BulkUpdateBuilder)
+}
+
+// ColumnType casts the values of column to typ, e.g. "int" or "timestamptz",
+// which Postgres needs to compare or assign values whose type it can't infer.
+// Only the values of the first row are cast, which sets the type of the column
+// for every row.
+func (b BulkUpdateBuilder) ColumnType(column, typ string) BulkUpdateBuilder {
+	return builder.Append(b, "ColumnTypes", columnType{column: column, typ: typ}).(BulkUpdateBuilder)
+}
+
+// Where adds WHERE expressions to the query, which are ANDed with the match of
+// the key columns.
+//
+// See SelectBuilder.Where for more information.
+func (b BulkUpdateBuilder) Where(pred interface{}, args ...interface{}) BulkUpdateBuilder {
+	return builder.Append(b, "WhereParts", newWherePart(pred, args...)).(BulkUpdateBuilder)
+}
+
+// Unscoped disables the default scopes of the StatementBuilderType the query
+// was created from.
+func (b BulkUpdateBuilder) Unscoped() BulkUpdateBuilder {
+	return builder.Set(b, "Unscoped", true).(BulkUpdateBuilder)
+}
+
+// MaxParams sets the maximum number of args of a query, which defaults to the
+// limit of its dialect: 65535 for Postgres and MySQL, 32766 for SQLite and
+// 2100 for SQL Server.
+func (b BulkUpdateBuilder) MaxParams(n int) BulkUpdateBuilder {
+	return builder.Set(b, "MaxParams", n).(BulkUpdateBuilder)
+}
+
+// Suffix adds an expression to the end of the query.
+func (b BulkUpdateBuilder) Suffix(sql string, args ...interface{}) BulkUpdateBuilder {
+	return builder.Append(b, "Suffixes", newPart(Expr(sql, args...))).(BulkUpdateBuilder)
+}
+
+// SuffixExpr adds an expression to the end of the query.
+func (b BulkUpdateBuilder) SuffixExpr(expr SQLizer) BulkUpdateBuilder {
+	return builder.Append(b, "Suffixes", newPart(expr)).(BulkUpdateBuilder)
+}
+
+// Comment adds tags to the trailing comment of the statement.
+//
+// See SelectBuilder.Comment for more information.
+func (b BulkUpdateBuilder) Comment(tags map[string]string) BulkUpdateBuilder {
+	return builder.Append(b, "Comments", tags).(BulkUpdateBuilder)
+}
+
+// Chunks splits the rows of the query into as few queries as possible whose
+// args don't exceed MaxParams, in order. A row with more args than that on
+// its own is left in a query of its own, whose ToSQL returns an error.
+//
+// Ex:
+//
+//	for _, chunk := range BulkUpdate("users", keys, cols, rows).Chunks() {
+//		sql, args, err := chunk.ToSQL()
+//		...
+//	}
+func (b BulkUpdateBuilder) Chunks() []BulkUpdateBuilder {
+	d := builder.GetStruct(b).(bulkUpdateData)
+	if len(d.Rows) <= 1 {
+		return []BulkUpdateBuilder{b}
+	}
+
+	// the args of anything but the rows are those of a query of one row, less
+	// the args of that row
+	rowArgs := make([]int, len(d.Rows))
+	for i, row := range d.Rows {
+		rowArgs[i] = countArgs(row)
+	}
+	_, args, err := b.withRows(d.Rows[:1]).ToSQL()
+	if err != nil {
+		return []BulkUpdateBuilder{b}
+	}
+	limit := d.maxParams() - (len(args) - rowArgs[0])
+
+	var chunks []BulkUpdateBuilder
+	start, n := 0, 0
+	for i := range d.Rows {
+		if i > start && n+rowArgs[i] > limit {
+			chunks = append(chunks, b.withRows(d.Rows[start:i]))
+			start, n = i, 0
+		}
+		n += rowArgs[i]
+	}
+	return append(chunks, b.withRows(d.Rows[start:]))
+}
+
+func (b BulkUpdateBuilder) withRows(rows [][]interface{}) BulkUpdateBuilder {
+	return builder.Set(b, "Rows", rows[:len(rows):len(rows)]).(BulkUpdateBuilder)
+}
+
+// countArgs returns the number of args of values.
+func countArgs(values []interface{}) int {
+	n := 0
+	for _, val := range values {
+		if vs, ok := val.(SQLizer); ok {
+			_, args, _ := vs.ToSQL()
+			n += len(args)
+		} else {
+			n++
+		}
+	}
+	return n
+}
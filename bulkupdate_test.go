@@ -0,0 +1,157 @@
+package sq
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var bulkRows = [][]interface{}{
+	{1, "moe", 13},
+	{2, "larry", Expr("NULL")},
+}
+
+func TestBulkUpdateBuilderToSQL(t *testing.T) {
+	b := BulkUpdate("users", []string{"id"}, []string{"name", "age"}, bulkRows).
+		Where(Eq{"users.active": true})
+
+	tests := []struct {
+		dialect Dialect
+		sql     string
+	}{
+		{
+			Postgres,
+			"UPDATE users SET name = v.name, age = v.age " +
+				"FROM (VALUES (?, ?, ?),(?, ?, NULL)) AS v(id, name, age) " +
+				"WHERE users.id = v.id AND users.active = ?",
+		},
+		{
+			SQLite,
+			"UPDATE users SET name = v.name, age = v.age " +
+				"FROM (SELECT ? AS id, ? AS name, ? AS age UNION ALL SELECT ?, ?, NULL) AS v " +
+				"WHERE users.id = v.id AND users.active = ?",
+		},
+		{
+			MySQL,
+			"UPDATE users JOIN (SELECT ? AS id, ? AS name, ? AS age UNION ALL SELECT ?, ?, NULL) AS v " +
+				"ON users.id = v.id SET users.name = v.name, users.age = v.age WHERE users.active = ?",
+		},
+		{
+			SQLServer,
+			"UPDATE users SET name = v.name, age = v.age " +
+				"FROM users JOIN (VALUES (?, ?, ?),(?, ?, NULL)) AS v(id, name, age) " +
+				"ON users.id = v.id WHERE users.active = ?",
+		},
+	}
+	for _, test := range tests {
+		sql, args, err := b.Dialect(test.dialect).ToSQL()
+		assert.NoError(t, err)
+		assert.Equal(t, test.sql, sql, "%s", test.dialect)
+		assert.Equal(t, []interface{}{1, "moe", 13, 2, "larry", true}, args)
+	}
+}
+
+func TestBulkUpdateBuilderCompositeKey(t *testing.T) {
+	sql, args, err := StatementBuilder.PlaceholderFormat(Dollar).
+		BulkUpdate("stock s", []string{"store_id", "sku"}, []string{"qty"}, [][]interface{}{
+			{1, "a", 5},
+			{1, "b", Expr("? + 1", 6)},
+		}).
+		ColumnType("qty", "int").
+		ColumnType("store_id", "bigint").
+		Suffix("RETURNING s.sku").
+		ToSQL()
+	assert.NoError(t, err)
+
+	expectedSQL := "UPDATE stock s SET qty = v.qty " +
+		"FROM (VALUES (CAST($1 AS bigint), $2, CAST($3 AS int)),($4, $5, $6 + 1)) AS v(store_id, sku, qty) " +
+		"WHERE s.store_id = v.store_id AND s.sku = v.sku " +
+		"RETURNING s.sku"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{1, "a", 5, 1, "b", 6}, args)
+}
+
+func TestBulkUpdateBuilderScopes(t *testing.T) {
+	sb := StatementBuilder.Scope("users", Eq{"tenant_id": 9}).Pagination(TopOffsetFetch)
+
+	sql, args, err := sb.BulkUpdate("users", []string{"id"}, []string{"name"}, [][]interface{}{{1, "moe"}}).ToSQL()
+	assert.NoError(t, err)
+	expectedSQL := "UPDATE users SET name = v.name FROM (VALUES (?, ?)) AS v(id, name) " +
+		"WHERE users.id = v.id AND users.tenant_id = ?"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{1, "moe", 9}, args)
+
+	sql, _, err = sb.BulkUpdate("users", []string{"id"}, []string{"name"}, [][]interface{}{{1, "moe"}}).Unscoped().ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = v.name FROM (VALUES (?, ?)) AS v(id, name) WHERE users.id = v.id", sql)
+}
+
+func TestBulkUpdateBuilderChunks(t *testing.T) {
+	rows := make([][]interface{}, 10)
+	for i := range rows {
+		rows[i] = []interface{}{i, i * 10}
+	}
+	b := BulkUpdate("t", []string{"id"}, []string{"n"}, rows).Where("t.n <> ?", -1).MaxParams(7)
+
+	_, _, err := b.ToSQL()
+	assert.Error(t, err)
+
+	chunks := b.Chunks()
+	assert.Len(t, chunks, 4)
+	var ids []interface{}
+	for i, chunk := range chunks {
+		sql, args, err := chunk.ToSQL()
+		assert.NoError(t, err)
+		assert.True(t, len(args) <= 7, "%d args", len(args))
+		assert.Equal(t, -1, args[len(args)-1])
+		for j := 0; j < len(args)-1; j += 2 {
+			ids = append(ids, args[j])
+		}
+		if i == 0 {
+			expectedSQL := "UPDATE t SET n = v.n FROM (VALUES (?, ?),(?, ?),(?, ?)) AS v(id, n) " +
+				"WHERE t.id = v.id AND t.n <> ?"
+			assert.Equal(t, expectedSQL, sql)
+		}
+	}
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, ids)
+
+	assert.Len(t, b.MaxParams(0).Chunks(), 1)
+}
+
+func TestBulkUpdateBuilderToSQLPretty(t *testing.T) {
+	sql, _, err := BulkUpdate("t", []string{"id"}, []string{"a", "b"}, [][]interface{}{{1, 2, 3}, {4, 5, 6}}).
+		Dialect(MySQL).
+		ToSQLPretty("  ")
+	assert.NoError(t, err)
+
+	expectedSQL := "UPDATE t\n" +
+		"JOIN (SELECT ? AS id, ? AS a, ? AS b UNION ALL\n" +
+		"  SELECT ?, ?, ?) AS v ON t.id = v.id\n" +
+		"SET t.a = v.a,\n" +
+		"  t.b = v.b"
+	assert.Equal(t, expectedSQL, sql)
+}
+
+func TestBulkUpdateBuilderErrors(t *testing.T) {
+	tests := []BulkUpdateBuilder{
+		BulkUpdate("", []string{"id"}, []string{"a"}, [][]interface{}{{1, 2}}),
+		BulkUpdate("t, u", []string{"id"}, []string{"a"}, [][]interface{}{{1, 2}}),
+		BulkUpdate("t v", []string{"id"}, []string{"a"}, [][]interface{}{{1, 2}}),
+		BulkUpdate("t", nil, []string{"a"}, [][]interface{}{{1, 2}}),
+		BulkUpdate("t", []string{"id"}, nil, [][]interface{}{{1, 2}}),
+		BulkUpdate("t", []string{"id"}, []string{"a"}, nil),
+		BulkUpdate("t", []string{"id"}, []string{"a"}, [][]interface{}{{1, 2}}).Dialect(Dialect(42)),
+		BulkUpdate("t", []string{"id"}, []string{"a"}, [][]interface{}{{1, Default}}),
+	}
+	for _, b := range tests {
+		_, _, err := b.ToSQL()
+		assert.Error(t, err)
+	}
+
+	_, _, err := BulkUpdate("t", []string{"id"}, []string{"a"}, [][]interface{}{{1, 2}, {3}}).ToSQL()
+	var buildErr *BuildError
+	assert.True(t, errors.As(err, &buildErr), "%v", err)
+	assert.Equal(t, "VALUES", buildErr.Clause)
+	assert.Equal(t, 1, buildErr.Index)
+}
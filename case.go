@@ -1,7 +1,6 @@
 package sq
 
 import (
-	"bytes"
 	"errors"
 
 	"github.com/lann/builder"
@@ -11,47 +10,12 @@ func init() {
 	builder.Register(CaseBuilder{}, caseData{})
 }
 
-// sqlizerBuffer is a helper that allows to write many SQLizers one by one
-// without constant checks for errors that may come from SQLizer.
-type sqlizerBuffer struct {
-	bytes.Buffer
-	args []interface{}
-	err  error
-}
-
-// WriteSQL converts SQLizer to SQL strings and writes it to buffer.
-func (b *sqlizerBuffer) WriteSQL(item SQLizer) {
-	if b.err != nil {
-		return
-	}
-
-	var str string
-	var args []interface{}
-	str, args, b.err = nestedToSQL(item)
-
-	if b.err != nil {
-		return
-	}
-
-	b.WriteString(str)
-	b.WriteByte(' ')
-	b.args = append(b.args, args...)
-}
-
-func (b *sqlizerBuffer) ToSQL() (string, []interface{}, error) {
-	return b.String(), b.args, b.err
-}
-
 // whenPart is a helper structure to describe SQLs "WHEN ... THEN ..." expression.
 type whenPart struct {
 	when SQLizer
 	then SQLizer
 }
 
-func newWhenPart(when interface{}, then interface{}) whenPart {
-	return whenPart{newPart(when), newPart(then)}
-}
-
 // caseData holds all the data required to build a CASE SQL construct.
 type caseData struct {
 	What      SQLizer
@@ -60,35 +24,37 @@ type caseData struct {
 }
 
 // ToSQL implements SQLizer.
-func (d *caseData) ToSQL() (sqlStr string, args []interface{}, err error) {
-	if len(d.WhenParts) == 0 {
-		err = errors.New("case expression must contain at lease one WHEN clause")
+func (d *caseData) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(d)
+}
 
+func (d *caseData) writeSQL(w *sqlWriter) {
+	if len(d.WhenParts) == 0 {
+		w.Fail(errors.New("case expression must contain at lease one WHEN clause"))
 		return
 	}
 
-	sql := sqlizerBuffer{}
-
-	sql.WriteString("CASE ")
+	w.WriteString("CASE ")
 	if d.What != nil {
-		sql.WriteSQL(d.What)
+		w.WriteSQL(d.What)
+		w.WriteByte(' ')
 	}
 
 	for _, p := range d.WhenParts {
-		sql.WriteString("WHEN ")
-		sql.WriteSQL(p.when)
-		sql.WriteString("THEN ")
-		sql.WriteSQL(p.then)
+		w.WriteString("WHEN ")
+		w.WriteSQL(p.when)
+		w.WriteString(" THEN ")
+		w.WriteSQL(p.then)
+		w.WriteByte(' ')
 	}
 
 	if d.Else != nil {
-		sql.WriteString("ELSE ")
-		sql.WriteSQL(d.Else)
+		w.WriteString("ELSE ")
+		w.WriteSQL(d.Else)
+		w.WriteByte(' ')
 	}
 
-	sql.WriteString("END")
-
-	return sql.ToSQL()
+	w.WriteString("END")
 }
 
 // CaseBuilder builds SQL CASE construct which could be used as parts of queries.
@@ -100,6 +66,11 @@ func (b CaseBuilder) ToSQL() (string, []interface{}, error) {
 	return data.ToSQL()
 }
 
+func (b CaseBuilder) writeSQL(w *sqlWriter) {
+	data := builder.GetStruct(b).(caseData)
+	data.writeSQL(w)
+}
+
 // MustSQL builds the query into a SQL string and bound args.
 // It panics if there are any errors.
 func (b CaseBuilder) MustSQL() (string, []interface{}) {
@@ -119,10 +90,25 @@ func (b CaseBuilder) what(expr interface{}) CaseBuilder {
 func (b CaseBuilder) When(when interface{}, then interface{}) CaseBuilder {
 	// TODO: performance hint: replace slice of WhenPart with just slice of parts
 	// where even indices of the slice belong to "when"s and odd indices belong to "then"s
-	return builder.Append(b, "WhenParts", newWhenPart(when, then)).(CaseBuilder)
+	return builder.Append(b, "WhenParts", whenPart{newPart(when), newPart(then)}).(CaseBuilder)
 }
 
 // Else sets optional "ELSE ..." part for CASE construct.
 func (b CaseBuilder) Else(expr interface{}) CaseBuilder {
 	return builder.Set(b, "Else", newPart(expr)).(CaseBuilder)
 }
+
+// Apply calls fn with the builder and returns its result, which allows reusing
+// functions that add clauses to a query.
+func (b CaseBuilder) Apply(fn func(CaseBuilder) CaseBuilder) CaseBuilder {
+	return fn(b)
+}
+
+// ApplyIf calls Apply if cond is true, and returns the builder unchanged
+// otherwise.
+func (b CaseBuilder) ApplyIf(cond bool, fn func(CaseBuilder) CaseBuilder) CaseBuilder {
+	if !cond {
+		return b
+	}
+	return b.Apply(fn)
+}
@@ -1,6 +1,7 @@
 package sq
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -137,7 +138,10 @@ func TestCaseWithNoWhenClause(t *testing.T) {
 
 	assert.Error(t, err)
 
-	assert.Equal(t, "case expression must contain at lease one WHEN clause", err.Error())
+	var buildErr *BuildError
+	assert.True(t, errors.As(err, &buildErr))
+	assert.Equal(t, "SELECT", buildErr.Clause)
+	assert.Equal(t, "case expression must contain at lease one WHEN clause", buildErr.Err.Error())
 }
 
 func TestCaseBuilderMustSQL(t *testing.T) {
@@ -0,0 +1,71 @@
+package sq
+
+import (
+	"sort"
+	"strings"
+)
+
+// sqlComment renders the tags returned by commenter and the tags of comments,
+// which take precedence, as a trailing comment in the sqlcommenter format:
+//
+//	/*key='value',other='value'*/
+//
+// It returns an empty string if there are no tags.
+func sqlComment(commenter func() map[string]string, comments []map[string]string) string {
+	var tags map[string]string
+	add := func(m map[string]string) {
+		for k, v := range m {
+			if tags == nil {
+				tags = make(map[string]string)
+			}
+			tags[k] = v
+		}
+	}
+	if commenter != nil {
+		add(commenter())
+	}
+	for _, m := range comments {
+		add(m)
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b := &strings.Builder{}
+	b.WriteString(" /*")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		escapeCommentTag(b, k)
+		b.WriteString("='")
+		escapeCommentTag(b, tags[k])
+		b.WriteByte('\'')
+	}
+	b.WriteString("*/")
+	return b.String()
+}
+
+// escapeCommentTag percent-encodes every byte of s except the unreserved
+// characters of RFC 3986. Quotes, "*", "/" and "?" are always encoded, so a tag
+// can neither terminate the comment nor be mistaken for a placeholder.
+func escapeCommentTag(b *strings.Builder, s string) {
+	const hex = "0123456789ABCDEF"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&15])
+	}
+}
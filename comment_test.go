@@ -0,0 +1,83 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComment(t *testing.T) {
+	tags := map[string]string{"route": "/users/:id", "controller": "users"}
+	expectedComment := " /*controller='users',route='%2Fusers%2F%3Aid'*/"
+
+	tests := []struct {
+		b   SQLizer
+		sql string
+	}{
+		{Select("id").From("users").Where("id = ?", 1).Comment(tags), "SELECT id FROM users WHERE id = ?"},
+		{Insert("users").Columns("id").Values(1).Comment(tags), "INSERT INTO users (id) VALUES (?)"},
+		{Update("users").Set("id", 1).Comment(tags), "UPDATE users SET id = ?"},
+		{Delete("users").Where("id = ?", 1).Comment(tags), "DELETE FROM users WHERE id = ?"},
+	}
+	for _, test := range tests {
+		sql, args, err := test.b.ToSQL()
+		assert.NoError(t, err)
+		assert.Equal(t, test.sql+expectedComment, sql)
+		assert.Equal(t, []interface{}{1}, args)
+	}
+}
+
+func TestCommentEscaping(t *testing.T) {
+	b := Select("id").From("users").Comment(map[string]string{
+		"evil*/ DROP TABLE users; /*": "x'*/; DROP TABLE users; --",
+		"q":                           "a?b $1 ü",
+	})
+
+	sql, _, err := b.PlaceholderFormat(Dollar).ToSQL()
+	assert.NoError(t, err)
+
+	expectedSQL := "SELECT id FROM users /*" +
+		"evil%2A%2F%20DROP%20TABLE%20users%3B%20%2F%2A='x%27%2A%2F%3B%20DROP%20TABLE%20users%3B%20--'," +
+		"q='a%3Fb%20%241%20%C3%BC'*/"
+	assert.Equal(t, expectedSQL, sql)
+}
+
+func TestCommentOverridesAndCommenter(t *testing.T) {
+	calls := 0
+	sb := StatementBuilder.Commenter(func() map[string]string {
+		calls++
+		return map[string]string{"traceparent": "00-abc-01", "route": "default"}
+	})
+
+	b := sb.Select("id").From("users").
+		Comment(map[string]string{"route": "first"}).
+		Comment(map[string]string{"route": "second"})
+
+	sql, _, err := b.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users /*route='second',traceparent='00-abc-01'*/", sql)
+
+	sql, _, err = sb.Insert("users").Values(1).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users VALUES (?) /*route='default',traceparent='00-abc-01'*/", sql)
+	assert.Equal(t, 2, calls)
+
+	sql, _, err = sb.Commenter(func() map[string]string { return nil }).Delete("users").ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users", sql)
+}
+
+func TestCommentNestedStatements(t *testing.T) {
+	sb := StatementBuilder.Commenter(func() map[string]string {
+		return map[string]string{"app": "api"}
+	})
+	sub := sb.Select("id").From("orgs").Comment(map[string]string{"sub": "1"})
+
+	sql, _, err := sb.Select("*").FromSelect(sub, "o").ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT id FROM orgs) AS o /*app='api'*/", sql)
+
+	sql, _, err = sb.Select("*").From("users").ToSQLPretty("  ")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT *\nFROM users /*app='api'*/", sql)
+}
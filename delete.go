@@ -1,7 +1,6 @@
 package sq
 
 import (
-	"bytes"
 	"fmt"
 	"strings"
 
@@ -10,68 +9,101 @@ import (
 
 type deleteData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
 	Prefixes          []SQLizer
 	From              string
 	WhereParts        []SQLizer
 	OrderBys          []string
-	Limit             string
-	Offset            string
+	Limit             interface{}
+	Offset            interface{}
+	BindLimits        bool
+	Pagination        Pagination
 	Suffixes          []SQLizer
+	Scopes            []scope
+	Unscoped          bool
+	Hints             []string
+	Comments          []map[string]string
+	Commenter         func() map[string]string
 }
 
 func (d *deleteData) ToSQL() (sqlStr string, args []interface{}, err error) {
-	if len(d.From) == 0 {
-		err = fmt.Errorf("delete statements must specify a From table")
+	sqlStr, args, err = rawToSQL(d)
+	if err != nil {
 		return
 	}
 
-	sql := &bytes.Buffer{}
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr += sqlComment(d.Commenter, d.Comments)
+	return
+}
 
-	if len(d.Prefixes) > 0 {
-		args, err = appendToSQL(d.Prefixes, sql, " ", args)
-		if err != nil {
-			return
-		}
+func (d *deleteData) toSQLPretty(indent string) (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = prettyToSQL(d, indent)
+	if err != nil {
+		return
+	}
 
-		sql.WriteString(" ")
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr += sqlComment(d.Commenter, d.Comments)
+	return
+}
+
+func (d *deleteData) writeSQL(w *sqlWriter) {
+	defer w.setDialect(d.Dialect)()
+
+	if len(d.From) == 0 {
+		w.Fail(fmt.Errorf("delete statements must specify a From table"))
+		return
 	}
 
-	sql.WriteString("DELETE FROM ")
-	sql.WriteString(d.From)
+	page := pagination{
+		style:   d.Pagination,
+		stmt:    "DELETE",
+		limit:   d.Limit,
+		offset:  d.Offset,
+		bind:    d.BindLimits,
+		ordered: len(d.OrderBys) > 0,
+	}
+	if err := page.check(); err != nil {
+		w.Fail(err)
+		return
+	}
 
-	if len(d.WhereParts) > 0 {
-		sql.WriteString(" WHERE ")
-		args, err = appendToSQL(d.WhereParts, sql, " AND ", args)
-		if err != nil {
-			return
-		}
+	if len(d.Prefixes) > 0 {
+		w.WriteClause("PREFIX", d.Prefixes, " ")
+		w.Break()
 	}
 
-	if len(d.OrderBys) > 0 {
-		sql.WriteString(" ORDER BY ")
-		sql.WriteString(strings.Join(d.OrderBys, ", "))
+	w.WriteString("DELETE ")
+	writeHints(w, d.Hints)
+	page.writeTop(w)
+	w.WriteString("FROM ")
+	w.WriteString(d.From)
+
+	whereParts := d.WhereParts
+	if !d.Unscoped && len(d.Scopes) > 0 {
+		preds := scopeTables(d.From, d.Scopes)
+		whereParts = append(whereParts[:len(whereParts):len(whereParts)], preds...)
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(d.Limit)
+	if len(whereParts) > 0 {
+		w.Break()
+		w.WriteString("WHERE ")
+		w.WriteConditions("WHERE", whereParts)
 	}
 
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
+	if len(d.OrderBys) > 0 {
+		w.Break()
+		w.WriteString("ORDER BY ")
+		w.WriteString(strings.Join(d.OrderBys, ", "))
 	}
 
+	page.writeSQL(w)
+
 	if len(d.Suffixes) > 0 {
-		sql.WriteString(" ")
-		args, err = appendToSQL(d.Suffixes, sql, " ", args)
-		if err != nil {
-			return
-		}
+		w.Break()
+		w.WriteClause("SUFFIX", d.Suffixes, " ")
 	}
-
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
-	return
 }
 
 // Builder
@@ -91,6 +123,13 @@ func (b DeleteBuilder) PlaceholderFormat(f PlaceholderFormat) DeleteBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(DeleteBuilder)
 }
 
+// Dialect sets the dialect of the query.
+//
+// See SelectBuilder.Dialect.
+func (b DeleteBuilder) Dialect(d Dialect) DeleteBuilder {
+	return builder.Set(b, "Dialect", d).(DeleteBuilder)
+}
+
 // SQL methods
 
 // ToSQL builds the query into a SQL string and bound args.
@@ -99,6 +138,19 @@ func (b DeleteBuilder) ToSQL() (string, []interface{}, error) {
 	return data.ToSQL()
 }
 
+// ToSQLPretty is like ToSQL, but puts each clause on its own line.
+//
+// See SelectBuilder.ToSQLPretty for more information.
+func (b DeleteBuilder) ToSQLPretty(indent string) (string, []interface{}, error) {
+	data := builder.GetStruct(b).(deleteData)
+	return data.toSQLPretty(indent)
+}
+
+func (b DeleteBuilder) writeSQL(w *sqlWriter) {
+	data := builder.GetStruct(b).(deleteData)
+	data.writeSQL(w)
+}
+
 // MustSQL builds the query into a SQL string and bound args.
 // It panics if there are any errors.
 func (b DeleteBuilder) MustSQL() (string, []interface{}) {
@@ -111,12 +163,12 @@ func (b DeleteBuilder) MustSQL() (string, []interface{}) {
 
 // Prefix adds an expression to the beginning of the query.
 func (b DeleteBuilder) Prefix(sql string, args ...interface{}) DeleteBuilder {
-	return b.PrefixExpr(Expr(sql, args...))
+	return builder.Append(b, "Prefixes", newPart(Expr(sql, args...))).(DeleteBuilder)
 }
 
 // PrefixExpr adds an expression to the very beginning of the query.
 func (b DeleteBuilder) PrefixExpr(expr SQLizer) DeleteBuilder {
-	return builder.Append(b, "Prefixes", expr).(DeleteBuilder)
+	return builder.Append(b, "Prefixes", newPart(expr)).(DeleteBuilder)
 }
 
 // From sets the table to be deleted from.
@@ -128,7 +180,34 @@ func (b DeleteBuilder) From(from string) DeleteBuilder {
 //
 // See SelectBuilder.Where for more information.
 func (b DeleteBuilder) Where(pred interface{}, args ...interface{}) DeleteBuilder {
-	return builder.Append(b, "WhereParts", newWherePart(pred, args...)).(DeleteBuilder)
+	return b.where(pred, args)
+}
+
+// WhereIf adds an expression to the WHERE clause of the query if cond is true.
+//
+// See Where.
+func (b DeleteBuilder) WhereIf(cond bool, pred interface{}, args ...interface{}) DeleteBuilder {
+	if !cond {
+		return b
+	}
+	return b.where(pred, args)
+}
+
+// where adds pred to the WHERE clause unless it is nil or empty, recording the
+// call to the method calling it.
+func (b DeleteBuilder) where(pred interface{}, args []interface{}) DeleteBuilder {
+	if pred == nil || pred == "" {
+		return b
+	}
+	p := &wherePart{pred: pred, args: args}
+	p.caller.capture(1)
+	return builder.Append(b, "WhereParts", p).(DeleteBuilder)
+}
+
+// Unscoped disables the default scopes of the StatementBuilderType the query
+// was created from.
+func (b DeleteBuilder) Unscoped() DeleteBuilder {
+	return builder.Set(b, "Unscoped", true).(DeleteBuilder)
 }
 
 // OrderBy adds ORDER BY expressions to the query.
@@ -138,20 +217,77 @@ func (b DeleteBuilder) OrderBy(orderBys ...string) DeleteBuilder {
 
 // Limit sets a LIMIT clause on the query.
 func (b DeleteBuilder) Limit(limit uint64) DeleteBuilder {
-	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(DeleteBuilder)
+	return builder.Set(b, "Limit", limit).(DeleteBuilder)
 }
 
 // Offset sets a OFFSET clause on the query.
 func (b DeleteBuilder) Offset(offset uint64) DeleteBuilder {
-	return builder.Set(b, "Offset", fmt.Sprintf("%d", offset)).(DeleteBuilder)
+	return builder.Set(b, "Offset", offset).(DeleteBuilder)
+}
+
+// LimitExpr sets a LIMIT clause on the query computed by expr.
+//
+// See SelectBuilder.LimitExpr.
+func (b DeleteBuilder) LimitExpr(expr SQLizer) DeleteBuilder {
+	return builder.Set(b, "Limit", newPart(expr)).(DeleteBuilder)
+}
+
+// OffsetExpr sets a OFFSET clause on the query computed by expr.
+//
+// See SelectBuilder.LimitExpr.
+func (b DeleteBuilder) OffsetExpr(expr SQLizer) DeleteBuilder {
+	return builder.Set(b, "Offset", newPart(expr)).(DeleteBuilder)
+}
+
+// BindLimits binds the values set by Limit and Offset as args instead of
+// writing them in the SQL.
+//
+// See SelectBuilder.BindLimits.
+func (b DeleteBuilder) BindLimits() DeleteBuilder {
+	return builder.Set(b, "BindLimits", true).(DeleteBuilder)
+}
+
+// Pagination sets the syntax of the LIMIT and OFFSET clauses of the query.
+//
+// See SelectBuilder.Pagination.
+func (b DeleteBuilder) Pagination(p Pagination) DeleteBuilder {
+	return builder.Set(b, "Pagination", p).(DeleteBuilder)
 }
 
 // Suffix adds an expression to the end of the query.
 func (b DeleteBuilder) Suffix(sql string, args ...interface{}) DeleteBuilder {
-	return b.SuffixExpr(Expr(sql, args...))
+	return builder.Append(b, "Suffixes", newPart(Expr(sql, args...))).(DeleteBuilder)
 }
 
 // SuffixExpr adds an expression to the end of the query.
 func (b DeleteBuilder) SuffixExpr(expr SQLizer) DeleteBuilder {
-	return builder.Append(b, "Suffixes", expr).(DeleteBuilder)
+	return builder.Append(b, "Suffixes", newPart(expr)).(DeleteBuilder)
+}
+
+// Hint adds a MySQL or Oracle optimizer hint, which is rendered in a /*+ */
+// comment right after DELETE.
+func (b DeleteBuilder) Hint(hint string) DeleteBuilder {
+	return builder.Append(b, "Hints", hint).(DeleteBuilder)
+}
+
+// Comment adds tags to the trailing comment of the statement.
+//
+// See SelectBuilder.Comment for more information.
+func (b DeleteBuilder) Comment(tags map[string]string) DeleteBuilder {
+	return builder.Append(b, "Comments", tags).(DeleteBuilder)
+}
+
+// Apply calls fn with the builder and returns its result, which allows reusing
+// functions that add clauses to a query.
+func (b DeleteBuilder) Apply(fn func(DeleteBuilder) DeleteBuilder) DeleteBuilder {
+	return fn(b)
+}
+
+// ApplyIf calls Apply if cond is true, and returns the builder unchanged
+// otherwise.
+func (b DeleteBuilder) ApplyIf(cond bool, fn func(DeleteBuilder) DeleteBuilder) DeleteBuilder {
+	if !cond {
+		return b
+	}
+	return b.Apply(fn)
 }
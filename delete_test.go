@@ -52,3 +52,25 @@ func TestDeleteBuilderPlaceholders(t *testing.T) {
 	sql, _, _ = b.PlaceholderFormat(Dollar).ToSQL()
 	assert.Equal(t, "DELETE FROM test WHERE x = $1 AND y = $2", sql)
 }
+
+func TestDeleteBuilderWhereIfApply(t *testing.T) {
+	sql, args, err := Delete("t").
+		WhereIf(false, "a = ?", 1).
+		WhereIf(true, "b = ?", 2).
+		Apply(func(b DeleteBuilder) DeleteBuilder { return b.Limit(3) }).
+		ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM t WHERE b = ? LIMIT 3", sql)
+	assert.Equal(t, []interface{}{2}, args)
+
+	sql, _, err = Delete("t").WhereIf(true, nil).WhereIf(true, "").Where(nil).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM t", sql)
+}
+
+func TestDeleteBuilderToSQLPretty(t *testing.T) {
+	sql, args, err := Delete("t").Where("a = ?", 1).Where("b = 1").Limit(2).ToSQLPretty("  ")
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM t\nWHERE a = ?\n  AND b = 1\nLIMIT 2", sql)
+	assert.Equal(t, []interface{}{1}, args)
+}
@@ -0,0 +1,33 @@
+package sq
+
+import "fmt"
+
+// Dialect is the SQL dialect of a database, for the features whose syntax
+// differs between databases.
+type Dialect int
+
+const (
+	// Postgres is the dialect of PostgreSQL.
+	Postgres Dialect = iota
+	// MySQL is the dialect of MySQL and MariaDB.
+	MySQL
+	// SQLite is the dialect of SQLite.
+	SQLite
+	// SQLServer is the dialect of Microsoft SQL Server.
+	SQLServer
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case Postgres:
+		return "Postgres"
+	case MySQL:
+		return "MySQL"
+	case SQLite:
+		return "SQLite"
+	case SQLServer:
+		return "SQLServer"
+	default:
+		return fmt.Sprintf("Dialect(%d)", int(d))
+	}
+}
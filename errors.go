@@ -0,0 +1,122 @@
+package sq
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// BuildError is returned by ToSQL when a part added to a query can't be
+// rendered, and tells where the part was added to the query.
+type BuildError struct {
+	// Clause is the clause the part belongs to, e.g. "WHERE".
+	Clause string
+	// Index is the index of the part within its clause.
+	Index int
+	// Caller is the "file:line" location of the builder method call which
+	// added the part, if known.
+	Caller string
+	// Err is the underlying error. It is itself a *BuildError or BuildErrors
+	// if the part is a nested query which couldn't be rendered.
+	Err error
+}
+
+func (e *BuildError) Error() string {
+	msg := fmt.Sprintf("%s[%d]", e.Clause, e.Index)
+	if e.Caller != "" {
+		msg += " added at " + e.Caller
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+// BuildErrors is returned by ToSQL when several parts of a query can't be
+// rendered. errors.Is and errors.As match any of them.
+type BuildErrors []*BuildError
+
+func (es BuildErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors matches target.
+func (es BuildErrors) Is(target error) bool {
+	for _, e := range es {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target.
+func (es BuildErrors) As(target interface{}) bool {
+	for _, e := range es {
+		if errors.As(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinBuildErrors returns the error for errs, or nil if there are none.
+func joinBuildErrors(errs []*BuildError) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return append(BuildErrors(nil), errs...)
+	}
+}
+
+// callSite is the program counter of a builder method call, which is only
+// symbolized if an error needs to report it.
+type callSite uintptr
+
+// capture records the call to the builder method skip frames above the
+// function calling capture, e.g. capture(0) in a builder method records the
+// call to it.
+func (c *callSite) capture(skip int) {
+	var pc [1]uintptr
+	if runtime.Callers(skip+3, pc[:]) == 1 {
+		*c = callSite(pc[0])
+	}
+}
+
+// String returns the "file:line" location of the call, or an empty string if
+// it is unknown or inside of this package.
+func (c *callSite) String() string {
+	if *c == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{uintptr(*c)}).Next()
+	if frame.File == "" || isInternalFile(frame.File) {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+}
+
+// siter is implemented by parts which record where they were added to a query.
+type siter interface {
+	site() *callSite
+}
+
+var pkgDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+func isInternalFile(file string) bool {
+	return filepath.Dir(file) == pkgDir && !strings.HasSuffix(file, "_test.go")
+}
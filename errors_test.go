@@ -0,0 +1,111 @@
+package sq
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTest = errors.New("test error")
+
+type errSQLizer struct{}
+
+func (errSQLizer) ToSQL() (string, []interface{}, error) {
+	return "", nil, errTest
+}
+
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+	return l
+}
+
+func TestBuildError(t *testing.T) {
+	b := Select("a").From("t").Where("x = ?", 1)
+	b, l := b.Where(1), line()
+
+	_, _, err := b.ToSQL()
+
+	var buildErr *BuildError
+	if assert.True(t, errors.As(err, &buildErr)) {
+		assert.Equal(t, "WHERE", buildErr.Clause)
+		assert.Equal(t, 1, buildErr.Index)
+		assert.Equal(t, fmt.Sprintf("%s:%d", pkgDir+"/errors_test.go", l), buildErr.Caller)
+		assert.EqualError(t, buildErr.Err, "expected string-keyed map or string, not int")
+	}
+}
+
+func TestBuildErrorCaller(t *testing.T) {
+	where, l1 := Select("a").From("t").WhereIf(true, 1), line()
+	set, l2 := Update("t").SetMap(map[string]interface{}{"a": errSQLizer{}}), line()
+	join, l3 := Select("a").From("t").LeftJoinUsing("u"), line()
+	when, l4 := Merge("t").Using("s", "").On("t.id = s.id").WhenMatched(1).Delete(), line()
+
+	tests := []struct {
+		b    SQLizer
+		line int
+	}{
+		{where, l1},
+		{set, l2},
+		{join, l3},
+		{when, l4},
+	}
+	for _, test := range tests {
+		_, _, err := test.b.ToSQL()
+
+		var buildErr *BuildError
+		if assert.True(t, errors.As(err, &buildErr), "%v", err) {
+			assert.Equal(t, fmt.Sprintf("%s:%d", pkgDir+"/errors_test.go", test.line), buildErr.Caller)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	_, _, err := Select("a").
+		Column(errSQLizer{}).
+		From("t").
+		Where(Lt{"x": nil}).
+		OrderByClause(errSQLizer{}).
+		ToSQL()
+
+	var buildErrs BuildErrors
+	if assert.True(t, errors.As(err, &buildErrs)) && assert.Len(t, buildErrs, 3) {
+		assert.Equal(t, "SELECT", buildErrs[0].Clause)
+		assert.Equal(t, 1, buildErrs[0].Index)
+		assert.Equal(t, "WHERE", buildErrs[1].Clause)
+		assert.Equal(t, "ORDER BY", buildErrs[2].Clause)
+	}
+	assert.True(t, errors.Is(err, errTest))
+
+	var buildErr *BuildError
+	assert.True(t, errors.As(err, &buildErr))
+	assert.Equal(t, "SELECT", buildErr.Clause)
+}
+
+func TestBuildErrorNested(t *testing.T) {
+	sub := Select("a").From("u").Where(errSQLizer{})
+	_, _, err := Update("t").Set("a", 1).Set("b", sub).Where("c = ?", 2).ToSQL()
+
+	var buildErr *BuildError
+	if assert.True(t, errors.As(err, &buildErr)) {
+		assert.Equal(t, "SET", buildErr.Clause)
+		assert.Equal(t, 1, buildErr.Index)
+		assert.Contains(t, buildErr.Caller, "errors_test.go")
+
+		var nested *BuildError
+		if assert.True(t, errors.As(buildErr.Err, &nested)) {
+			assert.Equal(t, "WHERE", nested.Clause)
+			assert.Equal(t, 0, nested.Index)
+		}
+	}
+	assert.True(t, errors.Is(err, errTest))
+}
+
+func TestBuildErrorStatement(t *testing.T) {
+	_, _, err := Select().From("t").ToSQL()
+
+	var buildErr *BuildError
+	assert.False(t, errors.As(err, &buildErr))
+}
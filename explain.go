@@ -0,0 +1,161 @@
+package sq
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+// ExplainOptions are the options of an EXPLAIN statement.
+type ExplainOptions struct {
+	// Dialect is the dialect of the EXPLAIN statement. If it is Postgres, the
+	// zero value, it defaults to the Dialect of the statement builder. SQLite
+	// only supports EXPLAIN QUERY PLAN, without options.
+	Dialect Dialect
+	// Analyze executes the statement to report actual run times. MySQL only
+	// supports it with the TREE format.
+	Analyze bool
+	// Format is the output format, e.g. "JSON", "TREE" or "TEXT".
+	Format string
+	// Verbose and Buffers are only supported by Postgres.
+	Verbose bool
+	Buffers bool
+}
+
+type explainExpr struct {
+	stmt SQLizer
+	opts ExplainOptions
+}
+
+// Explain wraps the statement s with EXPLAIN, keeping its args and the
+// placeholder format and comment of its builder. Prefixes of s such as WITH
+// clauses come after EXPLAIN.
+//
+// Ex:
+//
+//	Explain(Select("*").From("users"), ExplainOptions{Analyze: true, Format: "JSON"})
+//	// EXPLAIN (ANALYZE, FORMAT JSON) SELECT * FROM users
+func Explain(s SQLizer, opts ExplainOptions) SQLizer {
+	return explainExpr{stmt: s, opts: opts}
+}
+
+func (e explainExpr) ToSQL() (string, []interface{}, error) {
+	sql, args, err := rawToSQL(e)
+	if err != nil {
+		return "", nil, err
+	}
+	sql, err = placeholderFormatOf(e.stmt).ReplacePlaceholders(sql)
+	sql += commentOf(e.stmt)
+	return sql, args, err
+}
+
+func (e explainExpr) writeSQL(w *sqlWriter) {
+	if e.stmt == nil {
+		w.Fail(fmt.Errorf("explain requires a statement"))
+		return
+	}
+	opts := e.opts
+	if d, ok := statementOption(e.stmt, "Dialect").(Dialect); ok && opts.Dialect == Postgres {
+		opts.Dialect = d
+	}
+	keyword, err := opts.keyword()
+	if err != nil {
+		w.Fail(err)
+		return
+	}
+
+	w.WriteString(keyword)
+	w.WriteByte(' ')
+	if raw, ok := e.stmt.(rawSQLizer); ok {
+		raw.writeSQL(w)
+	} else {
+		w.WriteSQL(e.stmt)
+	}
+}
+
+var explainFormatPattern = regexp.MustCompile(`^[A-Za-z]+$`)
+
+// keyword returns the EXPLAIN keyword and options preceding the statement.
+func (o ExplainOptions) keyword() (string, error) {
+	if o.Format != "" && !explainFormatPattern.MatchString(o.Format) {
+		return "", fmt.Errorf("invalid explain format %q", o.Format)
+	}
+	format := strings.ToUpper(o.Format)
+
+	switch o.Dialect {
+	case Postgres:
+		var opts []string
+		if o.Analyze {
+			opts = append(opts, "ANALYZE")
+		}
+		if o.Verbose {
+			opts = append(opts, "VERBOSE")
+		}
+		if o.Buffers {
+			opts = append(opts, "BUFFERS")
+		}
+		if format != "" {
+			opts = append(opts, "FORMAT "+format)
+		}
+		switch {
+		case len(opts) == 0:
+			return "EXPLAIN", nil
+		case len(opts) == 1 && o.Analyze:
+			return "EXPLAIN ANALYZE", nil
+		default:
+			return "EXPLAIN (" + strings.Join(opts, ", ") + ")", nil
+		}
+	case MySQL:
+		if o.Verbose || o.Buffers {
+			return "", fmt.Errorf("explain options VERBOSE and BUFFERS are not supported by %s", o.Dialect)
+		}
+		if o.Analyze && format != "" && format != "TREE" {
+			return "", fmt.Errorf("explain ANALYZE only supports the TREE format for %s, got %s", o.Dialect, format)
+		}
+		keyword := "EXPLAIN"
+		if o.Analyze {
+			keyword += " ANALYZE"
+		}
+		if format != "" {
+			keyword += " FORMAT=" + format
+		}
+		return keyword, nil
+	case SQLite:
+		if o.Analyze || o.Verbose || o.Buffers || o.Format != "" {
+			return "", fmt.Errorf("explain options are not supported by %s", o.Dialect)
+		}
+		return "EXPLAIN QUERY PLAN", nil
+	default:
+		return "", fmt.Errorf("explain is not supported for %s", o.Dialect)
+	}
+}
+
+// placeholderFormatOf returns the placeholder format of the statement builder
+// s, or Question if s isn't one.
+func placeholderFormatOf(s SQLizer) PlaceholderFormat {
+	if f, ok := statementOption(s, "PlaceholderFormat").(PlaceholderFormat); ok {
+		return f
+	}
+	return Question
+}
+
+// commentOf returns the trailing comment of the statement builder s, or an
+// empty string if s isn't one.
+func commentOf(s SQLizer) string {
+	commenter, _ := statementOption(s, "Commenter").(func() map[string]string)
+	comments, _ := statementOption(s, "Comments").([]map[string]string)
+	return sqlComment(commenter, comments)
+}
+
+// statementOption returns the value of the field of the statement builder s,
+// or nil if s isn't one or the field isn't set.
+func statementOption(s SQLizer, field string) interface{} {
+	switch s.(type) {
+	case SelectBuilder, InsertBuilder, UpdateBuilder, DeleteBuilder, MergeBuilder, BulkUpdateBuilder:
+		v, _ := builder.Get(s, field)
+		return v
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	sel := Select("*").From("users").Where("id = ?", 1)
+
+	tests := []struct {
+		opts ExplainOptions
+		sql  string
+	}{
+		{ExplainOptions{}, "EXPLAIN SELECT * FROM users WHERE id = ?"},
+		{ExplainOptions{Analyze: true}, "EXPLAIN ANALYZE SELECT * FROM users WHERE id = ?"},
+		{ExplainOptions{Format: "json", Buffers: true}, "EXPLAIN (BUFFERS, FORMAT JSON) SELECT * FROM users WHERE id = ?"},
+		{ExplainOptions{Analyze: true, Verbose: true}, "EXPLAIN (ANALYZE, VERBOSE) SELECT * FROM users WHERE id = ?"},
+		{ExplainOptions{Dialect: MySQL}, "EXPLAIN SELECT * FROM users WHERE id = ?"},
+		{ExplainOptions{Dialect: MySQL, Format: "JSON"}, "EXPLAIN FORMAT=JSON SELECT * FROM users WHERE id = ?"},
+		{ExplainOptions{Dialect: MySQL, Analyze: true}, "EXPLAIN ANALYZE SELECT * FROM users WHERE id = ?"},
+		{ExplainOptions{Dialect: MySQL, Analyze: true, Format: "tree"}, "EXPLAIN ANALYZE FORMAT=TREE SELECT * FROM users WHERE id = ?"},
+		{ExplainOptions{Dialect: SQLite}, "EXPLAIN QUERY PLAN SELECT * FROM users WHERE id = ?"},
+	}
+
+	for _, test := range tests {
+		sql, args, err := Explain(sel, test.opts).ToSQL()
+		assert.NoError(t, err)
+		assert.Equal(t, test.sql, sql)
+		assert.Equal(t, []interface{}{1}, args)
+	}
+}
+
+func TestExplainPlaceholders(t *testing.T) {
+	cte := Select("id").From("orgs").Where("kind = ?", "a")
+	b := Update("users").
+		PrefixExpr(Expr("WITH o AS (?)", cte)).
+		Set("active", false).
+		Where("org_id IN (SELECT id FROM o) AND age > ?", 30).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := Explain(b, ExplainOptions{Analyze: true}).ToSQL()
+	assert.NoError(t, err)
+
+	expectedSQL := "EXPLAIN ANALYZE WITH o AS (SELECT id FROM orgs WHERE kind = $1) " +
+		"UPDATE users SET active = $2 WHERE org_id IN (SELECT id FROM o) AND age > $3"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{"a", false, 30}, args)
+}
+
+func TestExplainComment(t *testing.T) {
+	b := StatementBuilder.
+		Commenter(func() map[string]string { return map[string]string{"app": "api"} }).
+		Select("*").
+		From("users").
+		Comment(map[string]string{"route": "/users"})
+
+	sql, _, err := Explain(b, ExplainOptions{}).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "EXPLAIN SELECT * FROM users /*app='api',route='%2Fusers'*/", sql)
+}
+
+func TestExplainStatementDialect(t *testing.T) {
+	sql, _, err := Explain(Select("*").From("users").Dialect(MySQL), ExplainOptions{Format: "JSON"}).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "EXPLAIN FORMAT=JSON SELECT * FROM users", sql)
+
+	sql, _, err = Explain(StatementBuilder.Dialect(SQLite).Delete("users"), ExplainOptions{}).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "EXPLAIN QUERY PLAN DELETE FROM users", sql)
+
+	sql, _, err = Explain(Select("*").From("users").Dialect(SQLite), ExplainOptions{Dialect: MySQL}).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "EXPLAIN SELECT * FROM users", sql)
+}
+
+func TestExplainErrors(t *testing.T) {
+	sel := Select("*").From("users")
+
+	_, _, err := Explain(sel, ExplainOptions{Dialect: SQLite, Analyze: true}).ToSQL()
+	assert.Error(t, err)
+
+	_, _, err = Explain(sel, ExplainOptions{Dialect: MySQL, Buffers: true}).ToSQL()
+	assert.Error(t, err)
+
+	_, _, err = Explain(sel, ExplainOptions{Dialect: MySQL, Analyze: true, Format: "JSON"}).ToSQL()
+	assert.Error(t, err)
+
+	_, _, err = Explain(sel, ExplainOptions{Format: "JSON) DROP"}).ToSQL()
+	assert.Error(t, err)
+
+	_, _, err = Explain(nil, ExplainOptions{}).ToSQL()
+	assert.Error(t, err)
+
+	_, _, err = Explain(Select(), ExplainOptions{}).ToSQL()
+	assert.Error(t, err)
+}
@@ -1,7 +1,6 @@
 package sq
 
 import (
-	"bytes"
 	"database/sql/driver"
 	"fmt"
 	"reflect"
@@ -15,6 +14,25 @@ const (
 	sqlFalse = "(1=0)"
 )
 
+// Default is the DEFAULT keyword, for use as a value in UpdateBuilder.Set and
+// SetMap, and InsertBuilder.Values and SetMap, e.g.
+//
+//	Insert("users").Columns("name", "created_at").Values("moe", Default)
+//	// INSERT INTO users (name,created_at) VALUES (?,DEFAULT)
+var Default = defaultExpr{}
+
+// defaultExpr is the type of Default, which other packages can't give another
+// value.
+type defaultExpr struct{}
+
+func (defaultExpr) ToSQL() (string, []interface{}, error) {
+	return "DEFAULT", nil, nil
+}
+
+func (defaultExpr) writeSQL(w *sqlWriter) {
+	w.WriteString("DEFAULT")
+}
+
 type expr struct {
 	sql  string
 	args []interface{}
@@ -29,7 +47,7 @@ func Expr(sql string, args ...interface{}) SQLizer {
 	return expr{sql: sql, args: args}
 }
 
-func (e expr) ToSQL() (sql string, args []interface{}, err error) {
+func (e expr) ToSQL() (string, []interface{}, error) {
 	simple := true
 	for _, arg := range e.args {
 		if _, ok := arg.(SQLizer); ok {
@@ -39,37 +57,34 @@ func (e expr) ToSQL() (sql string, args []interface{}, err error) {
 	if simple {
 		return e.sql, e.args, nil
 	}
+	return rawToSQL(e)
+}
 
-	buf := &bytes.Buffer{}
+func (e expr) writeSQL(w *sqlWriter) {
 	ap := e.args
 	sp := e.sql
 
-	var isql string
-	var iargs []interface{}
-
-	for err == nil && len(ap) > 0 && len(sp) > 0 {
-		i := strings.Index(sp, "?")
+	for w.Err() == nil && len(ap) > 0 && len(sp) > 0 {
+		i := strings.IndexByte(sp, '?')
 		if i < 0 {
 			// no more placeholders
 			break
 		}
-		if len(sp) > i+1 && sp[i+1:i+2] == "?" {
+		if len(sp) > i+1 && sp[i+1] == '?' {
 			// escaped "??"; append it and step past
-			buf.WriteString(sp[:i+2])
+			w.WriteString(sp[:i+2])
 			sp = sp[i+2:]
 			continue
 		}
 
 		if as, ok := ap[0].(SQLizer); ok {
 			// sqlizer argument; expand it and append the result
-			isql, iargs, err = as.ToSQL()
-			buf.WriteString(sp[:i])
-			buf.WriteString(isql)
-			args = append(args, iargs...)
+			w.WriteString(sp[:i])
+			w.WriteSQL(as)
 		} else {
 			// normal argument; append it and the placeholder
-			buf.WriteString(sp[:i+1])
-			args = append(args, ap[0])
+			w.WriteString(sp[:i+1])
+			w.args = append(w.args, ap[0])
 		}
 
 		// step past the argument and placeholder
@@ -78,29 +93,27 @@ func (e expr) ToSQL() (sql string, args []interface{}, err error) {
 	}
 
 	// append the remaining sql and arguments
-	buf.WriteString(sp)
-	return buf.String(), append(args, ap...), err
+	w.WriteRaw(sp, ap, nil)
 }
 
 type concatExpr []interface{}
 
-func (ce concatExpr) ToSQL() (sql string, args []interface{}, err error) {
+func (ce concatExpr) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(ce)
+}
+
+func (ce concatExpr) writeSQL(w *sqlWriter) {
 	for _, part := range ce {
 		switch p := part.(type) {
 		case string:
-			sql += p
+			w.WriteString(p)
 		case SQLizer:
-			pSQL, pArgs, err := p.ToSQL()
-			if err != nil {
-				return "", nil, err
-			}
-			sql += pSQL
-			args = append(args, pArgs...)
+			w.WriteSQL(p)
 		default:
-			return "", nil, fmt.Errorf("%#v is not a string or SQLizer", part)
+			w.Fail(fmt.Errorf("%#v is not a string or SQLizer", part))
+			return
 		}
 	}
-	return
 }
 
 // ConcatExpr builds an expression by concatenating strings and other expressions.
@@ -127,26 +140,33 @@ func Alias(expr SQLizer, alias string) aliasExpr {
 	return aliasExpr{expr, alias}
 }
 
-func (e aliasExpr) ToSQL() (sql string, args []interface{}, err error) {
-	sql, args, err = e.expr.ToSQL()
-	if err == nil {
-		sql = fmt.Sprintf("(%s) AS %s", sql, e.alias)
+func (e aliasExpr) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(e)
+}
+
+func (e aliasExpr) writeSQL(w *sqlWriter) {
+	if isStatement(e.expr) {
+		w.WriteSubquery(e.expr)
+	} else {
+		w.WriteByte('(')
+		w.WriteSQL(e.expr)
+		w.WriteByte(')')
 	}
-	return
+	w.WriteString(" AS ")
+	w.WriteString(e.alias)
 }
 
 // Eq is syntactic sugar for use with Where/Having/Set methods.
 type Eq map[string]interface{}
 
-func (eq Eq) toSQL(useNotOpr bool) (sql string, args []interface{}, err error) {
+func (eq Eq) write(w *sqlWriter, useNotOpr bool) {
 	if len(eq) == 0 {
 		// Empty SQL{} evaluates to true.
-		sql = sqlTrue
+		w.WriteString(sqlTrue)
 		return
 	}
 
 	var (
-		exprs       []string
 		equalOpr    = "="
 		inOpr       = "IN"
 		nullOpr     = "IS"
@@ -160,16 +180,11 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []interface{}, err error) {
 		inEmptyExpr = sqlTrue
 	}
 
-	sortedKeys := getSortedKeys(eq)
-	for _, key := range sortedKeys {
-		var expr string
-		val := eq[key]
-
-		switch v := val.(type) {
-		case driver.Valuer:
-			if val, err = v.Value(); err != nil {
-				return
-			}
+	for i, key := range getSortedKeys(eq) {
+		val, err := valuerValue(eq[key])
+		if err != nil {
+			w.Fail(err)
+			return
 		}
 
 		r := reflect.ValueOf(val)
@@ -181,35 +196,45 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []interface{}, err error) {
 			}
 		}
 
+		if i > 0 {
+			w.WriteString(" AND ")
+		}
+
 		if val == nil {
-			expr = fmt.Sprintf("%s %s NULL", key, nullOpr)
-		} else {
-			if isListType(val) {
-				valVal := reflect.ValueOf(val)
-				if valVal.Len() == 0 {
-					expr = inEmptyExpr
-					if args == nil {
-						args = []interface{}{}
-					}
-				} else {
-					for i := 0; i < valVal.Len(); i++ {
-						args = append(args, valVal.Index(i).Interface())
-					}
-					expr = fmt.Sprintf("%s %s (%s)", key, inOpr, Placeholders(valVal.Len()))
+			writeOp(w, key, nullOpr)
+			w.WriteString(" NULL")
+		} else if isListType(val) {
+			valVal := reflect.ValueOf(val)
+			if valVal.Len() == 0 {
+				w.WriteString(inEmptyExpr)
+				if w.args == nil {
+					w.args = []interface{}{}
 				}
 			} else {
-				expr = fmt.Sprintf("%s %s ?", key, equalOpr)
-				args = append(args, val)
+				writeOp(w, key, inOpr)
+				w.WriteString(" (")
+				for i := 0; i < valVal.Len(); i++ {
+					if i > 0 {
+						w.WriteByte(',')
+					}
+					w.WriteArg(valVal.Index(i).Interface())
+				}
+				w.WriteByte(')')
 			}
+		} else {
+			writeOp(w, key, equalOpr)
+			w.WriteByte(' ')
+			w.WriteArg(val)
 		}
-		exprs = append(exprs, expr)
 	}
-	sql = strings.Join(exprs, " AND ")
-	return
 }
 
-func (eq Eq) ToSQL() (sql string, args []interface{}, err error) {
-	return eq.toSQL(false)
+func (eq Eq) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(eq)
+}
+
+func (eq Eq) writeSQL(w *sqlWriter) {
+	eq.write(w, false)
 }
 
 // NotEq is syntactic sugar for use with Where/Having/Set methods.
@@ -218,8 +243,12 @@ func (eq Eq) ToSQL() (sql string, args []interface{}, err error) {
 //	.Where(NotEq{"id": 1}) == "id <> 1"
 type NotEq Eq
 
-func (neq NotEq) ToSQL() (sql string, args []interface{}, err error) {
-	return Eq(neq).toSQL(true)
+func (neq NotEq) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(neq)
+}
+
+func (neq NotEq) writeSQL(w *sqlWriter) {
+	Eq(neq).write(w, true)
 }
 
 // Like is syntactic sugar for use with LIKE conditions.
@@ -228,38 +257,38 @@ func (neq NotEq) ToSQL() (sql string, args []interface{}, err error) {
 //	.Where(Like{"name": "%irrel"})
 type Like map[string]interface{}
 
-func (lk Like) toSQL(opr string) (sql string, args []interface{}, err error) {
-	var exprs []string
-	for key, val := range lk {
-		expr := ""
-
-		switch v := val.(type) {
-		case driver.Valuer:
-			if val, err = v.Value(); err != nil {
-				return
-			}
+func (lk Like) write(w *sqlWriter, opr string) {
+	for i, key := range getSortedKeys(lk) {
+		val, err := valuerValue(lk[key])
+		if err != nil {
+			w.Fail(err)
+			return
 		}
 
 		if val == nil {
-			err = fmt.Errorf("cannot use null with like operators")
+			w.Fail(fmt.Errorf("cannot use null with like operators"))
 			return
-		} else {
-			if isListType(val) {
-				err = fmt.Errorf("cannot use array or slice with like operators")
-				return
-			} else {
-				expr = fmt.Sprintf("%s %s ?", key, opr)
-				args = append(args, val)
-			}
 		}
-		exprs = append(exprs, expr)
+		if isListType(val) {
+			w.Fail(fmt.Errorf("cannot use array or slice with like operators"))
+			return
+		}
+
+		if i > 0 {
+			w.WriteString(" AND ")
+		}
+		writeOp(w, key, opr)
+		w.WriteByte(' ')
+		w.WriteArg(val)
 	}
-	sql = strings.Join(exprs, " AND ")
-	return
 }
 
-func (lk Like) ToSQL() (sql string, args []interface{}, err error) {
-	return lk.toSQL("LIKE")
+func (lk Like) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(lk)
+}
+
+func (lk Like) writeSQL(w *sqlWriter) {
+	lk.write(w, "LIKE")
 }
 
 // NotLike is syntactic sugar for use with LIKE conditions.
@@ -268,8 +297,12 @@ func (lk Like) ToSQL() (sql string, args []interface{}, err error) {
 //	.Where(NotLike{"name": "%irrel"})
 type NotLike Like
 
-func (nlk NotLike) ToSQL() (sql string, args []interface{}, err error) {
-	return Like(nlk).toSQL("NOT LIKE")
+func (nlk NotLike) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(nlk)
+}
+
+func (nlk NotLike) writeSQL(w *sqlWriter) {
+	Like(nlk).write(w, "NOT LIKE")
 }
 
 // ILike is syntactic sugar for use with ILIKE conditions.
@@ -278,8 +311,12 @@ func (nlk NotLike) ToSQL() (sql string, args []interface{}, err error) {
 //	.Where(ILike{"name": "sq%"})
 type ILike Like
 
-func (ilk ILike) ToSQL() (sql string, args []interface{}, err error) {
-	return Like(ilk).toSQL("ILIKE")
+func (ilk ILike) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(ilk)
+}
+
+func (ilk ILike) writeSQL(w *sqlWriter) {
+	Like(ilk).write(w, "ILIKE")
 }
 
 // NotILike is syntactic sugar for use with ILIKE conditions.
@@ -288,8 +325,12 @@ func (ilk ILike) ToSQL() (sql string, args []interface{}, err error) {
 //	.Where(NotILike{"name": "sq%"})
 type NotILike Like
 
-func (nilk NotILike) ToSQL() (sql string, args []interface{}, err error) {
-	return Like(nilk).toSQL("NOT ILIKE")
+func (nilk NotILike) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(nilk)
+}
+
+func (nilk NotILike) writeSQL(w *sqlWriter) {
+	Like(nilk).write(w, "NOT ILIKE")
 }
 
 // Lt is syntactic sugar for use with Where/Having/Set methods.
@@ -298,51 +339,48 @@ func (nilk NotILike) ToSQL() (sql string, args []interface{}, err error) {
 //	.Where(Lt{"id": 1})
 type Lt map[string]interface{}
 
-func (lt Lt) toSQL(opposite, orEq bool) (sql string, args []interface{}, err error) {
-	var (
-		exprs []string
-		opr   = "<"
-	)
+func (lt Lt) write(w *sqlWriter, opposite, orEq bool) {
+	opr := "<"
 
 	if opposite {
 		opr = ">"
 	}
 
 	if orEq {
-		opr = fmt.Sprintf("%s%s", opr, "=")
+		opr += "="
 	}
 
-	sortedKeys := getSortedKeys(lt)
-	for _, key := range sortedKeys {
-		var expr string
-		val := lt[key]
-
-		switch v := val.(type) {
-		case driver.Valuer:
-			if val, err = v.Value(); err != nil {
-				return
-			}
+	for i, key := range getSortedKeys(lt) {
+		val, err := valuerValue(lt[key])
+		if err != nil {
+			w.Fail(err)
+			return
 		}
 
 		if val == nil {
-			err = fmt.Errorf("cannot use null with less than or greater than operators")
+			w.Fail(fmt.Errorf("cannot use null with less than or greater than operators"))
 			return
 		}
 		if isListType(val) {
-			err = fmt.Errorf("cannot use array or slice with less than or greater than operators")
+			w.Fail(fmt.Errorf("cannot use array or slice with less than or greater than operators"))
 			return
 		}
-		expr = fmt.Sprintf("%s %s ?", key, opr)
-		args = append(args, val)
 
-		exprs = append(exprs, expr)
+		if i > 0 {
+			w.WriteString(" AND ")
+		}
+		writeOp(w, key, opr)
+		w.WriteByte(' ')
+		w.WriteArg(val)
 	}
-	sql = strings.Join(exprs, " AND ")
-	return
 }
 
-func (lt Lt) ToSQL() (sql string, args []interface{}, err error) {
-	return lt.toSQL(false, false)
+func (lt Lt) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(lt)
+}
+
+func (lt Lt) writeSQL(w *sqlWriter) {
+	lt.write(w, false, false)
 }
 
 // LtOrEq is syntactic sugar for use with Where/Having/Set methods.
@@ -351,8 +389,12 @@ func (lt Lt) ToSQL() (sql string, args []interface{}, err error) {
 //	.Where(LtOrEq{"id": 1}) == "id <= 1"
 type LtOrEq Lt
 
-func (ltOrEq LtOrEq) ToSQL() (sql string, args []interface{}, err error) {
-	return Lt(ltOrEq).toSQL(false, true)
+func (ltOrEq LtOrEq) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(ltOrEq)
+}
+
+func (ltOrEq LtOrEq) writeSQL(w *sqlWriter) {
+	Lt(ltOrEq).write(w, false, true)
 }
 
 // Gt is syntactic sugar for use with Where/Having/Set methods.
@@ -361,8 +403,12 @@ func (ltOrEq LtOrEq) ToSQL() (sql string, args []interface{}, err error) {
 //	.Where(Gt{"id": 1}) == "id > 1"
 type Gt Lt
 
-func (gt Gt) ToSQL() (sql string, args []interface{}, err error) {
-	return Lt(gt).toSQL(true, false)
+func (gt Gt) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(gt)
+}
+
+func (gt Gt) writeSQL(w *sqlWriter) {
+	Lt(gt).write(w, true, false)
 }
 
 // GtOrEq is syntactic sugar for use with Where/Having/Set methods.
@@ -371,45 +417,55 @@ func (gt Gt) ToSQL() (sql string, args []interface{}, err error) {
 //	.Where(GtOrEq{"id": 1}) == "id >= 1"
 type GtOrEq Lt
 
-func (gtOrEq GtOrEq) ToSQL() (sql string, args []interface{}, err error) {
-	return Lt(gtOrEq).toSQL(true, true)
+func (gtOrEq GtOrEq) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(gtOrEq)
+}
+
+func (gtOrEq GtOrEq) writeSQL(w *sqlWriter) {
+	Lt(gtOrEq).write(w, true, true)
 }
 
 type conj []SQLizer
 
-func (c conj) join(sep, defaultExpr string) (sql string, args []interface{}, err error) {
+func (c conj) join(w *sqlWriter, sep, defaultExpr string) {
 	if len(c) == 0 {
-		return defaultExpr, []interface{}{}, nil
-	}
-	var sqlParts []string
-	for _, sqlizer := range c {
-		partSQL, partArgs, err := nestedToSQL(sqlizer)
-		if err != nil {
-			return "", nil, err
-		}
-		if partSQL != "" {
-			sqlParts = append(sqlParts, partSQL)
-			args = append(args, partArgs...)
+		w.WriteString(defaultExpr)
+		if w.args == nil {
+			w.args = []interface{}{}
 		}
+		return
 	}
-	if len(sqlParts) > 0 {
-		sql = fmt.Sprintf("(%s)", strings.Join(sqlParts, sep))
+	mark := w.Len()
+	w.WriteByte('(')
+	w.WriteParts(c, w.alignedSep(sep))
+	if w.Len() == mark+1 {
+		// every part was empty
+		w.Truncate(mark)
+		return
 	}
-	return
+	w.WriteByte(')')
 }
 
 // And conjunction SQLizers
 type And conj
 
 func (a And) ToSQL() (string, []interface{}, error) {
-	return conj(a).join(" AND ", sqlTrue)
+	return rawToSQL(a)
+}
+
+func (a And) writeSQL(w *sqlWriter) {
+	conj(a).join(w, " AND ", sqlTrue)
 }
 
 // Or conjunction SQLizers.
 type Or conj
 
 func (o Or) ToSQL() (string, []interface{}, error) {
-	return conj(o).join(" OR ", sqlFalse)
+	return rawToSQL(o)
+}
+
+func (o Or) writeSQL(w *sqlWriter) {
+	conj(o).join(w, " OR ", sqlFalse)
 }
 
 func getSortedKeys(exp map[string]interface{}) []string {
@@ -421,6 +477,21 @@ func getSortedKeys(exp map[string]interface{}) []string {
 	return sortedKeys
 }
 
+// valuerValue resolves val through driver.Valuer if it implements it.
+func valuerValue(val interface{}) (interface{}, error) {
+	if v, ok := val.(driver.Valuer); ok {
+		return v.Value()
+	}
+	return val, nil
+}
+
+// writeOp writes "<key> <opr>" to w.
+func writeOp(w *sqlWriter, key, opr string) {
+	w.WriteString(key)
+	w.WriteByte(' ')
+	w.WriteString(opr)
+}
+
 func isListType(val interface{}) bool {
 	if driver.IsValue(val) {
 		return false
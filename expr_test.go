@@ -462,3 +462,20 @@ func ExampleEq() {
 		"company": 20,
 	})
 }
+
+func BenchmarkAndToSQL(b *testing.B) {
+	pred := And{
+		Eq{"a": 1, "b": []int{2, 3, 4}, "c": nil},
+		Or{Lt{"d": 5}, GtOrEq{"e": 6}},
+		Like{"f": "%g%"},
+		Expr("h = ?", 7),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := pred.ToSQL(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,448 @@
+// Package filter parses filter and sort expressions from API query strings
+// into sq predicates and ORDER BY expressions.
+//
+// A filter expression is a comma separated list of terms which are ANDed
+// together, e.g.
+//
+//	age>=30,status:in:active|pending,name~jo
+//
+// Each term is a public field name followed by an operator and a value. The
+// operator is either one of the symbols =, !=, >, >=, <, <=, ~ (contains) and
+// !~ (does not contain), or a name between colons: eq, ne, gt, gte, lt, lte,
+// like, nlike, in, nin and null (with a value of true or false).
+//
+// Several values separated by | are ORed together, except for the negated
+// operators (ne, nlike) for which they are ANDed, and for in and nin which
+// take a list. A literal ",", "|" or "\" in a value is escaped with "\".
+//
+// A sort expression is a comma separated list of field names, each optionally
+// prefixed with "-" for descending or "+" for ascending order, e.g.
+//
+//	-created_at,name
+//
+// Only the fields of an Allowlist can be used, and they are always rendered
+// with their configured column, so expressions coming straight from clients
+// can't inject SQL.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tnychn/sq"
+)
+
+// Type is the type of the values accepted by a field.
+type Type int
+
+const (
+	// String fields accept any value and support the contains operators.
+	String Type = iota
+	// Int fields accept base 10 integers.
+	Int
+	// Float fields accept floating point numbers.
+	Float
+	// Bool fields accept the values accepted by strconv.ParseBool.
+	Bool
+	// Time fields accept RFC 3339 timestamps and dates like "2006-01-02".
+	Time
+)
+
+func (t Type) String() string {
+	switch t {
+	case String:
+		return "string"
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case Bool:
+		return "bool"
+	case Time:
+		return "time"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// Field is a field which can be filtered and sorted on.
+type Field struct {
+	// Column is the SQL expression the field is rendered as.
+	Column string
+	// Type is the type of the values the field accepts.
+	Type Type
+}
+
+// Allowlist maps the public field names accepted in expressions to fields.
+type Allowlist map[string]Field
+
+var (
+	// ErrSyntax is returned for malformed terms.
+	ErrSyntax = errors.New("invalid syntax")
+	// ErrUnknownField is returned for fields missing from the Allowlist.
+	ErrUnknownField = errors.New("unknown field")
+	// ErrOperator is returned for unknown operators, or operators which can't
+	// be used with the type of the field.
+	ErrOperator = errors.New("invalid operator")
+	// ErrValue is returned for values which can't be parsed as the type of the
+	// field.
+	ErrValue = errors.New("invalid value")
+)
+
+// Error describes a rejected term of an expression, in terms suitable for
+// returning to the client.
+type Error struct {
+	// Term is the term as it appears in the expression.
+	Term string
+	// Field is the public name of the field of the term, if any.
+	Field string
+	// Err is one of ErrSyntax, ErrUnknownField, ErrOperator or ErrValue.
+	Err error
+	// Detail explains what is wrong with the term.
+	Detail string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%q: %s", e.Term, e.Err)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Errors is the list of every rejected term of an expression.
+type Errors []*Error
+
+func (es Errors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors matches target.
+func (es Errors) Is(target error) bool {
+	for _, e := range es {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target.
+func (es Errors) As(target interface{}) bool {
+	for _, e := range es {
+		if errors.As(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+type operator struct {
+	name    string
+	negated bool
+	list    bool
+}
+
+var operators = map[string]operator{
+	"eq":    {name: "eq"},
+	"ne":    {name: "ne", negated: true},
+	"gt":    {name: "gt"},
+	"gte":   {name: "gte"},
+	"lt":    {name: "lt"},
+	"lte":   {name: "lte"},
+	"like":  {name: "like"},
+	"nlike": {name: "nlike", negated: true},
+	"in":    {name: "in", list: true},
+	"nin":   {name: "nin", list: true},
+	"null":  {name: "null"},
+}
+
+// symbols maps operator symbols to operator names, longest symbols first.
+var symbols = []struct{ symbol, name string }{
+	{">=", "gte"},
+	{"<=", "lte"},
+	{"!=", "ne"},
+	{"!~", "nlike"},
+	{">", "gt"},
+	{"<", "lt"},
+	{"=", "eq"},
+	{"~", "like"},
+}
+
+// Parse parses a filter expression into a predicate for use with Where.
+// It returns nil for an empty expression, and Errors listing every rejected
+// term otherwise.
+func (a Allowlist) Parse(expr string) (sq.SQLizer, error) {
+	var (
+		preds sq.And
+		errs  Errors
+	)
+	for _, term := range split(expr, ',') {
+		if term == "" {
+			continue
+		}
+		pred, err := a.parseTerm(term)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		preds = append(preds, pred)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(preds) == 0 {
+		return nil, nil
+	}
+	return preds, nil
+}
+
+// ParseSort parses a sort expression into ORDER BY expressions for use with
+// OrderBy. It returns Errors listing every rejected field.
+func (a Allowlist) ParseSort(expr string) ([]string, error) {
+	var (
+		orderBys []string
+		errs     Errors
+	)
+	for _, term := range strings.Split(expr, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		name, dir := term, "ASC"
+		switch term[0] {
+		case '-':
+			name, dir = term[1:], "DESC"
+		case '+':
+			name = term[1:]
+		}
+
+		field, ok := a[name]
+		if !ok {
+			errs = append(errs, &Error{Term: term, Field: name, Err: ErrUnknownField})
+			continue
+		}
+		orderBys = append(orderBys, field.Column+" "+dir)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return orderBys, nil
+}
+
+// Apply adds the predicate of the filter expression and the ORDER BY
+// expressions of the sort expression to b.
+func (a Allowlist) Apply(b sq.SelectBuilder, filter, sort string) (sq.SelectBuilder, error) {
+	pred, err := a.Parse(filter)
+	if err != nil {
+		return b, err
+	}
+	orderBys, err := a.ParseSort(sort)
+	if err != nil {
+		return b, err
+	}
+	return b.Where(pred).OrderBy(orderBys...), nil
+}
+
+func (a Allowlist) parseTerm(term string) (sq.SQLizer, *Error) {
+	i := 0
+	for i < len(term) && isNameByte(term[i]) {
+		i++
+	}
+	name, rest := term[:i], term[i:]
+	if name == "" {
+		return nil, &Error{Term: term, Err: ErrSyntax, Detail: "missing field name"}
+	}
+
+	var opName, raw string
+	if strings.HasPrefix(rest, ":") {
+		j := strings.IndexByte(rest[1:], ':')
+		if j < 0 {
+			return nil, &Error{Term: term, Field: name, Err: ErrSyntax, Detail: `expected "field:operator:value"`}
+		}
+		opName, raw = rest[1:j+1], rest[j+2:]
+	} else {
+		for _, s := range symbols {
+			if strings.HasPrefix(rest, s.symbol) {
+				opName, raw = s.name, rest[len(s.symbol):]
+				break
+			}
+		}
+		if opName == "" {
+			return nil, &Error{Term: term, Field: name, Err: ErrSyntax, Detail: "missing operator"}
+		}
+	}
+
+	field, ok := a[name]
+	if !ok {
+		return nil, &Error{Term: term, Field: name, Err: ErrUnknownField}
+	}
+	op, ok := operators[opName]
+	if !ok {
+		return nil, &Error{Term: term, Field: name, Err: ErrOperator, Detail: fmt.Sprintf("unknown operator %q", opName)}
+	}
+
+	if op.name == "null" {
+		isNull, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, &Error{Term: term, Field: name, Err: ErrValue, Detail: "expected true or false"}
+		}
+		if isNull {
+			return sq.Eq{field.Column: nil}, nil
+		}
+		return sq.NotEq{field.Column: nil}, nil
+	}
+
+	switch op.name {
+	case "like", "nlike":
+		if field.Type != String {
+			return nil, &Error{Term: term, Field: name, Err: ErrOperator, Detail: fmt.Sprintf("%s can't be used with %s fields", op.name, field.Type)}
+		}
+	case "gt", "gte", "lt", "lte":
+		if field.Type == Bool {
+			return nil, &Error{Term: term, Field: name, Err: ErrOperator, Detail: fmt.Sprintf("%s can't be used with %s fields", op.name, field.Type)}
+		}
+	}
+
+	var vals []interface{}
+	for _, s := range split(raw, '|') {
+		v, err := field.parseValue(unescape(s))
+		if err != nil {
+			return nil, &Error{Term: term, Field: name, Err: ErrValue, Detail: err.Error()}
+		}
+		vals = append(vals, v)
+	}
+
+	if op.list {
+		if op.name == "in" {
+			return sq.Eq{field.Column: vals}, nil
+		}
+		return sq.NotEq{field.Column: vals}, nil
+	}
+
+	preds := make([]sq.SQLizer, len(vals))
+	for i, v := range vals {
+		preds[i] = field.predicate(op.name, v)
+	}
+	if len(preds) == 1 {
+		return preds[0], nil
+	}
+	if op.negated {
+		return sq.And(preds), nil
+	}
+	return sq.Or(preds), nil
+}
+
+func (f Field) predicate(op string, v interface{}) sq.SQLizer {
+	switch op {
+	case "ne":
+		return sq.NotEq{f.Column: v}
+	case "gt":
+		return sq.Gt{f.Column: v}
+	case "gte":
+		return sq.GtOrEq{f.Column: v}
+	case "lt":
+		return sq.Lt{f.Column: v}
+	case "lte":
+		return sq.LtOrEq{f.Column: v}
+	case "like":
+		return sq.Like{f.Column: "%" + escapeLike(v.(string)) + "%"}
+	case "nlike":
+		return sq.NotLike{f.Column: "%" + escapeLike(v.(string)) + "%"}
+	default:
+		return sq.Eq{f.Column: v}
+	}
+}
+
+func (f Field) parseValue(s string) (interface{}, error) {
+	switch f.Type {
+	case Int:
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %q", s)
+		}
+		return v, nil
+	case Float:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", s)
+		}
+		return v, nil
+	case Bool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got %q", s)
+		}
+		return v, nil
+	case Time:
+		if v, err := time.Parse(time.RFC3339, s); err == nil {
+			return v, nil
+		}
+		v, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, fmt.Errorf("expected a date or RFC 3339 timestamp, got %q", s)
+		}
+		return v, nil
+	default:
+		return s, nil
+	}
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || c == '.' ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// split splits s around unescaped occurrences of sep, keeping escapes intact.
+func split(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescape removes the escaping backslashes from s.
+func unescape(s string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+	b := &strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the LIKE wildcards in s with backslashes, the default
+// escape character of Postgres and MySQL.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
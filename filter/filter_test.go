@@ -0,0 +1,140 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tnychn/sq"
+)
+
+var testAllowlist = Allowlist{
+	"age":        {Column: "users.age", Type: Int},
+	"score":      {Column: "users.score", Type: Float},
+	"status":     {Column: "users.status", Type: String},
+	"name":       {Column: "users.name", Type: String},
+	"admin":      {Column: "users.is_admin", Type: Bool},
+	"created_at": {Column: "users.created_at", Type: Time},
+}
+
+func TestParse(t *testing.T) {
+	pred, err := testAllowlist.Parse("age>=30,status:in:a|b,name~jo")
+	assert.NoError(t, err)
+
+	sql, args, err := pred.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "(users.age >= ? AND users.status IN (?,?) AND users.name LIKE ?)", sql)
+	assert.Equal(t, []interface{}{int64(30), "a", "b", "%jo%"}, args)
+}
+
+func TestParseOperators(t *testing.T) {
+	tests := []struct {
+		expr string
+		sql  string
+		args []interface{}
+	}{
+		{"age=1", "users.age = ?", []interface{}{int64(1)}},
+		{"age:eq:1", "users.age = ?", []interface{}{int64(1)}},
+		{"age!=1", "users.age <> ?", []interface{}{int64(1)}},
+		{"age>1", "users.age > ?", []interface{}{int64(1)}},
+		{"age<1", "users.age < ?", []interface{}{int64(1)}},
+		{"age<=1", "users.age <= ?", []interface{}{int64(1)}},
+		{"age:lte:1", "users.age <= ?", []interface{}{int64(1)}},
+		{"score>1.5", "users.score > ?", []interface{}{1.5}},
+		{"admin=true", "users.is_admin = ?", []interface{}{true}},
+		{"name!~a\\_b", "users.name NOT LIKE ?", []interface{}{"%a\\_b%"}},
+		{"age:nin:1|2", "users.age NOT IN (?,?)", []interface{}{int64(1), int64(2)}},
+		{"status=a|b", "(users.status = ? OR users.status = ?)", []interface{}{"a", "b"}},
+		{"status!=a|b", "(users.status <> ? AND users.status <> ?)", []interface{}{"a", "b"}},
+		{"status=a\\|b\\,c", "users.status = ?", []interface{}{"a|b,c"}},
+		{"status:null:true", "users.status IS NULL", nil},
+		{"status:null:false", "users.status IS NOT NULL", nil},
+		{
+			"created_at>2020-01-02",
+			"users.created_at > ?",
+			[]interface{}{time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	for _, test := range tests {
+		pred, err := testAllowlist.Parse(test.expr)
+		if !assert.NoError(t, err, test.expr) {
+			continue
+		}
+		sql, args, err := pred.ToSQL()
+		assert.NoError(t, err, test.expr)
+		assert.Equal(t, "("+test.sql+")", sql, test.expr)
+		assert.Equal(t, test.args, args, test.expr)
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	pred, err := testAllowlist.Parse("")
+	assert.NoError(t, err)
+	assert.Nil(t, pred)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr  string
+		field string
+		err   error
+	}{
+		{"=1", "", ErrSyntax},
+		{"age", "age", ErrSyntax},
+		{"age:gt", "age", ErrSyntax},
+		{"password=x", "password", ErrUnknownField},
+		{"age:foo:1", "age", ErrOperator},
+		{"age~1", "age", ErrOperator},
+		{"admin>true", "admin", ErrOperator},
+		{"age=x", "age", ErrValue},
+		{"age:null:maybe", "age", ErrValue},
+		{"created_at>yesterday", "created_at", ErrValue},
+	}
+
+	for _, test := range tests {
+		_, err := testAllowlist.Parse(test.expr)
+		assert.True(t, errors.Is(err, test.err), "%s: %v", test.expr, err)
+
+		var errs Errors
+		if assert.True(t, errors.As(err, &errs), test.expr) && assert.Len(t, errs, 1) {
+			assert.Equal(t, test.expr, errs[0].Term)
+			assert.Equal(t, test.field, errs[0].Field)
+		}
+	}
+}
+
+func TestParseMultipleErrors(t *testing.T) {
+	_, err := testAllowlist.Parse("age=x,name=ok,secret=1")
+
+	var errs Errors
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 2)
+	assert.Equal(t, `"age=x": invalid value: expected an integer, got "x"; "secret=1": unknown field`, err.Error())
+
+	var fe *Error
+	if assert.True(t, errors.As(err, &fe)) {
+		assert.Equal(t, "age=x", fe.Term)
+		assert.Equal(t, ErrValue, fe.Err)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	orderBys, err := testAllowlist.ParseSort("-created_at,+name,age")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"users.created_at DESC", "users.name ASC", "users.age ASC"}, orderBys)
+
+	_, err = testAllowlist.ParseSort("-password")
+	assert.True(t, errors.Is(err, ErrUnknownField))
+}
+
+func TestApply(t *testing.T) {
+	b, err := testAllowlist.Apply(sq.Select("*").From("users"), "age>30", "-age")
+	assert.NoError(t, err)
+
+	sql, args, err := b.PlaceholderFormat(sq.Dollar).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE (users.age > $1) ORDER BY users.age DESC", sql)
+	assert.Equal(t, []interface{}{int64(30)}, args)
+}
@@ -0,0 +1,161 @@
+package sq
+
+// funcExpr is a call of a SQL function with columns or expressions as its
+// arguments.
+type funcExpr struct {
+	name     string
+	distinct bool
+	args     []interface{}
+}
+
+func newFuncExpr(name string, args ...interface{}) SQLizer {
+	return funcExpr{name: name, args: args}
+}
+
+func (f funcExpr) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(f)
+}
+
+func (f funcExpr) writeSQL(w *sqlWriter) {
+	w.WriteString(f.name)
+	w.WriteByte('(')
+	if f.distinct {
+		w.WriteString("DISTINCT ")
+	}
+	for i, arg := range f.args {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+		part{pred: arg}.writeSQL(w)
+	}
+	w.WriteByte(')')
+}
+
+// castExpr is a CAST(... AS ...) expression.
+type castExpr struct {
+	expr interface{}
+	typ  string
+}
+
+func (c castExpr) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(c)
+}
+
+func (c castExpr) writeSQL(w *sqlWriter) {
+	w.WriteString("CAST(")
+	part{pred: c.expr}.writeSQL(w)
+	w.WriteString(" AS ")
+	w.WriteString(c.typ)
+	w.WriteByte(')')
+}
+
+// The function helpers below accept either a column name (or any other SQL
+// fragment) as a string, or a SQLizer whose args are bound in place, e.g.
+//
+//	Select().Column(Alias(Count("*"), "n")).From("users").GroupBy("org_id").
+//		Having(Expr("? > ?", Sum(Expr("score * ?", 2)), 100))
+
+// Count builds a COUNT(expr) expression.
+func Count(expr interface{}) SQLizer {
+	return newFuncExpr("COUNT", expr)
+}
+
+// CountDistinct builds a COUNT(DISTINCT expr) expression.
+func CountDistinct(expr interface{}) SQLizer {
+	return funcExpr{name: "COUNT", distinct: true, args: []interface{}{expr}}
+}
+
+// Sum builds a SUM(expr) expression.
+func Sum(expr interface{}) SQLizer {
+	return newFuncExpr("SUM", expr)
+}
+
+// Avg builds an AVG(expr) expression.
+func Avg(expr interface{}) SQLizer {
+	return newFuncExpr("AVG", expr)
+}
+
+// Min builds a MIN(expr) expression.
+func Min(expr interface{}) SQLizer {
+	return newFuncExpr("MIN", expr)
+}
+
+// Max builds a MAX(expr) expression.
+func Max(expr interface{}) SQLizer {
+	return newFuncExpr("MAX", expr)
+}
+
+// Coalesce builds a COALESCE(exprs...) expression.
+func Coalesce(exprs ...interface{}) SQLizer {
+	return newFuncExpr("COALESCE", exprs...)
+}
+
+// NullIf builds a NULLIF(expr1, expr2) expression.
+func NullIf(expr1, expr2 interface{}) SQLizer {
+	return newFuncExpr("NULLIF", expr1, expr2)
+}
+
+// Greatest builds a GREATEST(exprs...) expression.
+func Greatest(exprs ...interface{}) SQLizer {
+	return newFuncExpr("GREATEST", exprs...)
+}
+
+// Least builds a LEAST(exprs...) expression.
+func Least(exprs ...interface{}) SQLizer {
+	return newFuncExpr("LEAST", exprs...)
+}
+
+// Cast builds a CAST(expr AS typ) expression.
+func Cast(expr interface{}, typ string) SQLizer {
+	return castExpr{expr: expr, typ: typ}
+}
+
+// Lower builds a LOWER(expr) expression.
+func Lower(expr interface{}) SQLizer {
+	return newFuncExpr("LOWER", expr)
+}
+
+// Upper builds an UPPER(expr) expression.
+func Upper(expr interface{}) SQLizer {
+	return newFuncExpr("UPPER", expr)
+}
+
+// Grouping builds a GROUPING(exprs...) expression, which tells the rows of
+// subtotals of grouping sets from the others.
+func Grouping(exprs ...interface{}) SQLizer {
+	return newFuncExpr("GROUPING", exprs...)
+}
+
+// Rollup builds a ROLLUP(exprs...) grouping element for GroupByClause, which
+// groups by every prefix of exprs.
+//
+// Ex:
+//
+//	Select("region", "city", "SUM(amount)").From("sales").GroupByClause(Rollup("region", "city"))
+//	// SELECT region, city, SUM(amount) FROM sales GROUP BY ROLLUP(region, city)
+func Rollup(exprs ...interface{}) SQLizer {
+	return newFuncExpr("ROLLUP", exprs...)
+}
+
+// Cube builds a CUBE(exprs...) grouping element for GroupByClause, which
+// groups by every subset of exprs.
+func Cube(exprs ...interface{}) SQLizer {
+	return newFuncExpr("CUBE", exprs...)
+}
+
+// GroupingSets builds a GROUPING SETS(sets...) grouping element for
+// GroupByClause. Each set is an expression or a GroupingSet.
+//
+// Ex:
+//
+//	GroupingSets(GroupingSet("region", "city"), "region", GroupingSet())
+//	// GROUPING SETS((region, city), region, ())
+func GroupingSets(sets ...interface{}) SQLizer {
+	return newFuncExpr("GROUPING SETS", sets...)
+}
+
+// GroupingSet builds a parenthesized set of expressions for GroupingSets,
+// or the empty set () if there are none.
+func GroupingSet(exprs ...interface{}) SQLizer {
+	return newFuncExpr("", exprs...)
+}
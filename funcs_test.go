@@ -0,0 +1,63 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuncsToSQL(t *testing.T) {
+	tests := []struct {
+		expr SQLizer
+		sql  string
+		args []interface{}
+	}{
+		{Count("*"), "COUNT(*)", nil},
+		{CountDistinct("user_id"), "COUNT(DISTINCT user_id)", nil},
+		{Sum(Expr("price * ?", 2)), "SUM(price * ?)", []interface{}{2}},
+		{Avg("score"), "AVG(score)", nil},
+		{Min("a"), "MIN(a)", nil},
+		{Max("a"), "MAX(a)", nil},
+		{Coalesce("nickname", "name", Expr("?", "anon")), "COALESCE(nickname, name, ?)", []interface{}{"anon"}},
+		{NullIf("a", "0"), "NULLIF(a, 0)", nil},
+		{Greatest("a", Expr("?", 1)), "GREATEST(a, ?)", []interface{}{1}},
+		{Least("a", "b"), "LEAST(a, b)", nil},
+		{Cast(Expr("?", "1"), "INTEGER"), "CAST(? AS INTEGER)", []interface{}{"1"}},
+		{Lower(Upper("name")), "LOWER(UPPER(name))", nil},
+		{Grouping("region", "city"), "GROUPING(region, city)", nil},
+		{Rollup("region", Expr("date_trunc(?, at)", "day")), "ROLLUP(region, date_trunc(?, at))", []interface{}{"day"}},
+		{Cube("a", "b"), "CUBE(a, b)", nil},
+		{GroupingSets(GroupingSet("a", "b"), "a", GroupingSet()), "GROUPING SETS((a, b), a, ())", nil},
+	}
+
+	for _, test := range tests {
+		sql, args, err := test.expr.ToSQL()
+		assert.NoError(t, err)
+		assert.Equal(t, test.sql, sql)
+		assert.Equal(t, test.args, args)
+	}
+}
+
+func TestFuncsInSelect(t *testing.T) {
+	sql, args, err := Select("org_id").
+		Column(Alias(Count("*"), "n")).
+		Column(Alias(Sum(Expr("score * ?", 2)), "total")).
+		From("users").
+		Where("active = ?", true).
+		GroupBy("org_id").
+		Having(Expr("? > ?", Count("*"), 10)).
+		OrderByClause(Expr("? DESC", Max("created_at"))).
+		PlaceholderFormat(Dollar).
+		ToSQL()
+	assert.NoError(t, err)
+
+	expectedSQL := "SELECT org_id, (COUNT(*)) AS n, (SUM(score * $1)) AS total FROM users " +
+		"WHERE active = $2 GROUP BY org_id HAVING COUNT(*) > $3 ORDER BY MAX(created_at) DESC"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{2, true, 10}, args)
+}
+
+func TestFuncsErr(t *testing.T) {
+	_, _, err := Coalesce("a", 1).ToSQL()
+	assert.Error(t, err)
+}
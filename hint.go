@@ -0,0 +1,101 @@
+package sq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UseIndex returns a MySQL index hint for SelectBuilder.FromHint and JoinHint.
+//
+// Ex:
+//
+//	Select("*").From("users u").FromHint(UseIndex("idx_email"))
+//	// SELECT * FROM users u USE INDEX (idx_email)
+func UseIndex(indexes ...string) string {
+	return "USE INDEX (" + strings.Join(indexes, ", ") + ")"
+}
+
+// ForceIndex returns a MySQL index hint for SelectBuilder.FromHint and
+// JoinHint.
+func ForceIndex(indexes ...string) string {
+	return "FORCE INDEX (" + strings.Join(indexes, ", ") + ")"
+}
+
+// IgnoreIndex returns a MySQL index hint for SelectBuilder.FromHint and
+// JoinHint.
+func IgnoreIndex(indexes ...string) string {
+	return "IGNORE INDEX (" + strings.Join(indexes, ", ") + ")"
+}
+
+// TableHint returns a SQL Server table hint for SelectBuilder.FromHint and
+// JoinHint.
+//
+// Ex:
+//
+//	Select("*").From("users").FromHint(TableHint("NOLOCK"))
+//	// SELECT * FROM users WITH (NOLOCK)
+func TableHint(hints ...string) string {
+	return "WITH (" + strings.Join(hints, ", ") + ")"
+}
+
+// isTableHint reports whether word starts an index or table hint following a
+// table name.
+func isTableHint(word string) bool {
+	switch strings.ToUpper(word) {
+	case "USE", "FORCE", "IGNORE", "WITH":
+		return true
+	}
+	return false
+}
+
+// writeHints writes the optimizer hints as a /*+ ... */ comment followed by a
+// space.
+func writeHints(w *sqlWriter, hints []string) {
+	if len(hints) == 0 {
+		return
+	}
+	for _, hint := range hints {
+		if strings.Contains(hint, "*/") {
+			w.Fail(fmt.Errorf("optimizer hint %q must not contain \"*/\"", hint))
+			return
+		}
+		// a placeholder in a comment would be numbered without an arg
+		if strings.Contains(hint, "?") {
+			w.Fail(fmt.Errorf("optimizer hint %q must not contain placeholders", hint))
+			return
+		}
+	}
+	w.WriteString("/*+ ")
+	w.WriteString(strings.Join(hints, " "))
+	w.WriteString(" */ ")
+}
+
+// hintJoin returns join with hint inserted after its joined table.
+func hintJoin(join SQLizer, hint string) SQLizer {
+	if j, ok := join.(*joinExpr); ok {
+		return j.withHint(hint)
+	}
+	p, ok := join.(*part)
+	if !ok {
+		return errorSQLizer{fmt.Errorf("join hint %q requires a JOIN clause given as a string or by a join method", hint)}
+	}
+	s, ok := p.pred.(string)
+	if !ok {
+		return errorSQLizer{fmt.Errorf("join hint %q requires a JOIN clause given as a string or by a join method", hint)}
+	}
+	start, end := joinTarget(s)
+	if start < 0 {
+		return errorSQLizer{fmt.Errorf("join hint %q requires a JOIN clause, not %q", hint, s)}
+	}
+	return &part{pred: s[:end] + " " + hint + s[end:], args: p.args, caller: p.caller}
+}
+
+// errorSQLizer is a SQLizer failing with err, for errors which can only be
+// reported once the query is built.
+type errorSQLizer struct {
+	err error
+}
+
+func (e errorSQLizer) ToSQL() (string, []interface{}, error) {
+	return "", nil, e.err
+}
@@ -0,0 +1,111 @@
+package sq
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimizerHints(t *testing.T) {
+	tests := []struct {
+		b   SQLizer
+		sql string
+	}{
+		{
+			Select("*").Options("DISTINCT").From("users").Hint("MAX_EXECUTION_TIME(1000)").Hint("NO_ICP(users)"),
+			"SELECT /*+ MAX_EXECUTION_TIME(1000) NO_ICP(users) */ DISTINCT * FROM users",
+		},
+		{
+			Update("users").Set("a", 1).Hint("NO_MERGE()"),
+			"UPDATE /*+ NO_MERGE() */ users SET a = ?",
+		},
+		{
+			Delete("users").Where("id = ?", 1).Hint("BKA(users)"),
+			"DELETE /*+ BKA(users) */ FROM users WHERE id = ?",
+		},
+		{
+			Select("*").Prefix("WITH x AS (SELECT 1)").From("x").Hint("SET_VAR(sort_buffer_size = 16M)"),
+			"WITH x AS (SELECT 1) SELECT /*+ SET_VAR(sort_buffer_size = 16M) */ * FROM x",
+		},
+	}
+	for _, test := range tests {
+		sql, _, err := test.b.ToSQL()
+		assert.NoError(t, err)
+		assert.Equal(t, test.sql, sql)
+	}
+
+	_, _, err := Select("*").From("users").Hint("X */ DROP TABLE users; /*").ToSQL()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").From("users").Where("id = ?", 1).Hint("X(?)").PlaceholderFormat(Dollar).ToSQL()
+	assert.Error(t, err)
+}
+
+func TestTableHints(t *testing.T) {
+	sql, _, err := Select("*").From("users u").FromHint(UseIndex("idx_email", "idx_name")).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users u USE INDEX (idx_email, idx_name)", sql)
+
+	sql, _, err = Select("*").From("users").FromHint(TableHint("NOLOCK")).Where("id = ?", 1).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WITH (NOLOCK) WHERE id = ?", sql)
+
+	sql, args, err := Select("*").
+		From("users u").
+		Join("orgs o ON o.id = u.org_id AND o.kind = ?", "a").
+		JoinHint(ForceIndex("PRIMARY")).
+		LeftJoin("teams t USING (team_id)").
+		JoinHint(IgnoreIndex("idx_team")).
+		CrossJoin("roles r").
+		JoinHint(TableHint("NOLOCK")).
+		ToSQL()
+	assert.NoError(t, err)
+	expectedSQL := "SELECT * FROM users u " +
+		"JOIN orgs o FORCE INDEX (PRIMARY) ON o.id = u.org_id AND o.kind = ? " +
+		"LEFT JOIN teams t IGNORE INDEX (idx_team) USING (team_id) " +
+		"CROSS JOIN roles r WITH (NOLOCK)"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{"a"}, args)
+
+	_, _, err = Select("*").From("users").JoinHint(UseIndex("x")).ToSQL()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").From("users").JoinClause(Expr("JOIN orgs")).JoinHint(UseIndex("x")).ToSQL()
+	assert.Error(t, err)
+}
+
+func TestTableHintsWithScopes(t *testing.T) {
+	sb := StatementBuilder.Scope("orgs", Eq{"deleted_at": nil})
+
+	sql, _, err := sb.Select("*").From("users").Join("orgs ON orgs.id = users.org_id").JoinHint(UseIndex("PRIMARY")).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users JOIN orgs USE INDEX (PRIMARY) ON orgs.id = users.org_id WHERE orgs.deleted_at IS NULL", sql)
+}
+
+func TestHintsJSON(t *testing.T) {
+	b := Select("*").From("users").Hint("MAX_EXECUTION_TIME(1000)").FromHint(UseIndex("idx_email"))
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+
+	var decoded SelectBuilder
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	sql, _, err := decoded.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT /*+ MAX_EXECUTION_TIME(1000) */ * FROM users USE INDEX (idx_email)", sql)
+
+	err = json.Unmarshal([]byte(`{"version":1,"columns":["*"],"from":"users","fromHints":["WHERE 1=1"]}`), &decoded)
+	assert.Error(t, err)
+
+	doc := `{"version":1,"columns":["*"],"from":"users","hints":["BKA(users u)","SET_VAR(sort_buffer_size = 16M)"]}`
+	err = json.Unmarshal([]byte(doc), &decoded)
+	assert.True(t, errors.Is(err, ErrRawSQL), "%v", err)
+	assert.Contains(t, err.Error(), "SET_VAR")
+
+	decoded, err = JSONDecoder{AllowRaw: true}.DecodeSelect([]byte(doc))
+	assert.NoError(t, err)
+	sql, _, err = decoded.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT /*+ BKA(users u) SET_VAR(sort_buffer_size = 16M) */ * FROM users", sql)
+}
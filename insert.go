@@ -1,10 +1,8 @@
 package sq
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
-	"io"
 	"sort"
 	"strings"
 
@@ -13,125 +11,214 @@ import (
 
 type insertData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
 	Prefixes          []SQLizer
 	StatementKeyword  string
 	Options           []string
 	Into              string
 	Columns           []string
 	Values            [][]interface{}
+	DefaultValues     bool
+	Maps              []map[string]interface{}
+	MissingAsNull     bool
+	StrictMaps        bool
 	Suffixes          []SQLizer
 	Select            *SelectBuilder
+	Comments          []map[string]string
+	Commenter         func() map[string]string
 }
 
 func (d *insertData) ToSQL() (sqlStr string, args []interface{}, err error) {
-	if len(d.Into) == 0 {
-		err = errors.New("insert statements must specify a table")
+	sqlStr, args, err = rawToSQL(d)
+	if err != nil {
 		return
 	}
-	if len(d.Values) == 0 && d.Select == nil {
-		err = errors.New("insert statements must have at least one set of values or select clause")
+
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr += sqlComment(d.Commenter, d.Comments)
+	return
+}
+
+func (d *insertData) toSQLPretty(indent string) (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = prettyToSQL(d, indent)
+	if err != nil {
 		return
 	}
 
-	sql := &bytes.Buffer{}
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr += sqlComment(d.Commenter, d.Comments)
+	return
+}
 
-	if len(d.Prefixes) > 0 {
-		args, err = appendToSQL(d.Prefixes, sql, " ", args)
-		if err != nil {
+func (d *insertData) writeSQL(w *sqlWriter) {
+	if len(d.Maps) > 0 {
+		d.writeMapsSQL(w)
+		return
+	}
+
+	defer w.setDialect(d.Dialect)()
+
+	if len(d.Into) == 0 {
+		w.Fail(errors.New("insert statements must specify a table"))
+		return
+	}
+	if d.DefaultValues {
+		if len(d.Columns) > 0 || len(d.Values) > 0 || d.Select != nil {
+			w.Fail(errors.New("insert statements with default values can't have columns, values or a select clause"))
 			return
 		}
+	} else if len(d.Values) == 0 && d.Select == nil {
+		w.Fail(errors.New("insert statements must have at least one set of values or select clause"))
+		return
+	}
 
-		sql.WriteString(" ")
+	if len(d.Prefixes) > 0 {
+		w.WriteClause("PREFIX", d.Prefixes, " ")
+		w.Break()
 	}
 
 	if d.StatementKeyword == "" {
-		sql.WriteString("INSERT ")
+		w.WriteString("INSERT ")
 	} else {
-		sql.WriteString(d.StatementKeyword)
-		sql.WriteString(" ")
+		w.WriteString(d.StatementKeyword)
+		w.WriteString(" ")
 	}
 
 	if len(d.Options) > 0 {
-		sql.WriteString(strings.Join(d.Options, " "))
-		sql.WriteString(" ")
+		w.WriteString(strings.Join(d.Options, " "))
+		w.WriteString(" ")
 	}
 
-	sql.WriteString("INTO ")
-	sql.WriteString(d.Into)
-	sql.WriteString(" ")
+	w.WriteString("INTO ")
+	w.WriteString(d.Into)
+
+	if d.DefaultValues {
+		d.writeDefaultValuesSQL(w)
+	} else {
+		if len(d.Columns) > 0 {
+			w.WriteString(" (")
+			w.WriteString(strings.Join(d.Columns, ","))
+			w.WriteString(")")
+		}
+		w.Break()
 
-	if len(d.Columns) > 0 {
-		sql.WriteString("(")
-		sql.WriteString(strings.Join(d.Columns, ","))
-		sql.WriteString(") ")
+		if d.Select != nil {
+			d.writeSelectSQL(w)
+		} else {
+			d.writeValuesSQL(w)
+		}
 	}
 
-	if d.Select != nil {
-		args, err = d.appendSelectToSQL(sql, args)
-	} else {
-		args, err = d.appendValuesToSQL(sql, args)
+	if len(d.Suffixes) > 0 {
+		w.Break()
+		w.WriteClause("SUFFIX", d.Suffixes, " ")
 	}
-	if err != nil {
+}
+
+// writeMapsSQL writes the statement with the columns and rows of the maps set
+// by SetMaps.
+func (d *insertData) writeMapsSQL(w *sqlWriter) {
+	if len(d.Columns) > 0 || len(d.Values) > 0 {
+		w.Fail(errors.New("insert statements with SetMaps can't have other columns or values"))
 		return
 	}
 
-	if len(d.Suffixes) > 0 {
-		sql.WriteString(" ")
-		args, err = appendToSQL(d.Suffixes, sql, " ", args)
-		if err != nil {
-			return
+	seen := map[string]bool{}
+	var cols []string
+	for _, row := range d.Maps {
+		for col := range row {
+			if !seen[col] {
+				seen[col] = true
+				cols = append(cols, col)
+			}
 		}
 	}
+	if len(cols) == 0 {
+		w.Fail(errors.New("insert statements with SetMaps must have at least one column"))
+		return
+	}
+	sort.Strings(cols)
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
-	return
+	var missing interface{} = Default
+	if d.MissingAsNull {
+		missing = Expr("NULL")
+	}
+
+	values := make([][]interface{}, len(d.Maps))
+	for r, row := range d.Maps {
+		if d.StrictMaps && len(row) != len(cols) {
+			for _, col := range cols {
+				if _, ok := row[col]; !ok {
+					w.Fail(fmt.Errorf("row %d of SetMaps is missing column %s", r, col))
+					return
+				}
+			}
+		}
+		vals := make([]interface{}, len(cols))
+		for i, col := range cols {
+			if val, ok := row[col]; ok {
+				vals[i] = val
+			} else {
+				vals[i] = missing
+			}
+		}
+		values[r] = vals
+	}
+
+	e := *d
+	e.Columns, e.Values, e.Maps = cols, values, nil
+	e.writeSQL(w)
 }
 
-func (d *insertData) appendValuesToSQL(w io.Writer, args []interface{}) ([]interface{}, error) {
+func (d *insertData) writeDefaultValuesSQL(w *sqlWriter) {
+	// MySQL has no DEFAULT VALUES, but a row of no columns has the same effect
+	if d.Dialect == MySQL {
+		w.WriteString(" ()")
+		w.Break()
+		w.WriteString("VALUES ()")
+		return
+	}
+	w.Break()
+	w.WriteString("DEFAULT VALUES")
+}
+
+func (d *insertData) writeValuesSQL(w *sqlWriter) {
 	if len(d.Values) == 0 {
-		return args, errors.New("values for insert statements are not set")
+		w.Fail(errors.New("values for insert statements are not set"))
+		return
 	}
 
-	io.WriteString(w, "VALUES ")
+	w.WriteString("VALUES ")
 
-	valuesStrings := make([]string, len(d.Values))
+	sep := w.ListSep(",")
 	for r, row := range d.Values {
-		valueStrings := make([]string, len(row))
+		m := w.mark()
+		if r > 0 {
+			w.WriteString(sep)
+		}
+		w.WriteByte('(')
 		for v, val := range row {
+			if v > 0 {
+				w.WriteByte(',')
+			}
 			if vs, ok := val.(SQLizer); ok {
-				vsql, vargs, err := vs.ToSQL()
-				if err != nil {
-					return nil, err
-				}
-				valueStrings[v] = vsql
-				args = append(args, vargs...)
+				w.WriteSQL(vs)
 			} else {
-				valueStrings[v] = "?"
-				args = append(args, val)
+				w.WriteArg(val)
 			}
 		}
-		valuesStrings[r] = fmt.Sprintf("(%s)", strings.Join(valueStrings, ","))
+		w.WriteByte(')')
+		w.recordPartError(m, "VALUES", r, nil)
 	}
-
-	io.WriteString(w, strings.Join(valuesStrings, ","))
-
-	return args, nil
 }
 
-func (d *insertData) appendSelectToSQL(w io.Writer, args []interface{}) ([]interface{}, error) {
+func (d *insertData) writeSelectSQL(w *sqlWriter) {
 	if d.Select == nil {
-		return args, errors.New("select clause for insert statements are not set")
-	}
-
-	selectClause, sArgs, err := d.Select.ToSQL()
-	if err != nil {
-		return args, err
+		w.Fail(errors.New("select clause for insert statements are not set"))
+		return
 	}
 
-	io.WriteString(w, selectClause)
-	args = append(args, sArgs...)
-
-	return args, nil
+	d.Select.writeSQL(w)
 }
 
 // Builder
@@ -151,6 +238,13 @@ func (b InsertBuilder) PlaceholderFormat(f PlaceholderFormat) InsertBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(InsertBuilder)
 }
 
+// Dialect sets the dialect of the query.
+//
+// See SelectBuilder.Dialect.
+func (b InsertBuilder) Dialect(d Dialect) InsertBuilder {
+	return builder.Set(b, "Dialect", d).(InsertBuilder)
+}
+
 // SQL methods
 
 // ToSQL builds the query into a SQL string and bound args.
@@ -159,6 +253,20 @@ func (b InsertBuilder) ToSQL() (string, []interface{}, error) {
 	return data.ToSQL()
 }
 
+// ToSQLPretty is like ToSQL, but puts each row of VALUES and each clause on
+// its own line.
+//
+// See SelectBuilder.ToSQLPretty for more information.
+func (b InsertBuilder) ToSQLPretty(indent string) (string, []interface{}, error) {
+	data := builder.GetStruct(b).(insertData)
+	return data.toSQLPretty(indent)
+}
+
+func (b InsertBuilder) writeSQL(w *sqlWriter) {
+	data := builder.GetStruct(b).(insertData)
+	data.writeSQL(w)
+}
+
 // MustSQL builds the query into a SQL string and bound args.
 // It panics if there are any errors.
 func (b InsertBuilder) MustSQL() (string, []interface{}) {
@@ -171,12 +279,12 @@ func (b InsertBuilder) MustSQL() (string, []interface{}) {
 
 // Prefix adds an expression to the beginning of the query.
 func (b InsertBuilder) Prefix(sql string, args ...interface{}) InsertBuilder {
-	return b.PrefixExpr(Expr(sql, args...))
+	return builder.Append(b, "Prefixes", newPart(Expr(sql, args...))).(InsertBuilder)
 }
 
 // PrefixExpr adds an expression to the very beginning of the query.
 func (b InsertBuilder) PrefixExpr(expr SQLizer) InsertBuilder {
-	return builder.Append(b, "Prefixes", expr).(InsertBuilder)
+	return builder.Append(b, "Prefixes", newPart(expr)).(InsertBuilder)
 }
 
 // Options adds keyword options before the INTO clause of the query.
@@ -199,14 +307,23 @@ func (b InsertBuilder) Values(values ...interface{}) InsertBuilder {
 	return builder.Append(b, "Values", values).(InsertBuilder)
 }
 
+// DefaultValues inserts a single row of the default values of every column,
+// e.g. INSERT INTO t DEFAULT VALUES, or INSERT INTO t () VALUES () on MySQL.
+// It can't be used with Columns, Values or Select.
+//
+// To use the default value of some columns only, use Default as their value.
+func (b InsertBuilder) DefaultValues() InsertBuilder {
+	return builder.Set(b, "DefaultValues", true).(InsertBuilder)
+}
+
 // Suffix adds an expression to the end of the query.
 func (b InsertBuilder) Suffix(sql string, args ...interface{}) InsertBuilder {
-	return b.SuffixExpr(Expr(sql, args...))
+	return builder.Append(b, "Suffixes", newPart(Expr(sql, args...))).(InsertBuilder)
 }
 
 // SuffixExpr adds an expression to the end of the query.
 func (b InsertBuilder) SuffixExpr(expr SQLizer) InsertBuilder {
-	return builder.Append(b, "Suffixes", expr).(InsertBuilder)
+	return builder.Append(b, "Suffixes", newPart(expr)).(InsertBuilder)
 }
 
 // SetMap set columns and values for insert builder from a map of column name and value/
@@ -224,18 +341,74 @@ func (b InsertBuilder) SetMap(clauses map[string]interface{}) InsertBuilder {
 		vals = append(vals, clauses[col])
 	}
 
+	b = builder.Delete(b, "Maps").(InsertBuilder)
 	b = builder.Set(b, "Columns", cols).(InsertBuilder)
 	b = builder.Set(b, "Values", [][]interface{}{vals}).(InsertBuilder)
 
 	return b
 }
 
+// SetMaps sets the rows of the query from maps of column name and value, e.g.
+// records of different shapes. Like SetMap, it resets all previous columns and
+// values.
+//
+// The columns are the union of the keys of every map, sorted. The columns
+// missing from a row are set to Default, or NULL with MissingAsNull, unless
+// StrictMaps is set, in which case they are an error of ToSQL.
+//
+// Ex:
+//
+//	Insert("users").SetMaps([]map[string]interface{}{
+//		{"name": "moe", "age": 13},
+//		{"name": "larry"},
+//	})
+//	// INSERT INTO users (age,name) VALUES (?,?),(DEFAULT,?)
+func (b InsertBuilder) SetMaps(rows []map[string]interface{}) InsertBuilder {
+	b = builder.Delete(b, "Columns").(InsertBuilder)
+	b = builder.Delete(b, "Values").(InsertBuilder)
+	return builder.Set(b, "Maps", rows).(InsertBuilder)
+}
+
+// MissingAsNull makes SetMaps set the columns missing from a row to NULL
+// instead of DEFAULT, e.g. for SQLite which has no DEFAULT keyword.
+func (b InsertBuilder) MissingAsNull() InsertBuilder {
+	return builder.Set(b, "MissingAsNull", true).(InsertBuilder)
+}
+
+// StrictMaps makes ToSQL return an error if the maps of SetMaps don't all
+// have the same keys.
+func (b InsertBuilder) StrictMaps() InsertBuilder {
+	return builder.Set(b, "StrictMaps", true).(InsertBuilder)
+}
+
 // Select set Select clause for insert query.
 // If Values and Select are used, then Select has higher priority.
 func (b InsertBuilder) Select(sb SelectBuilder) InsertBuilder {
 	return builder.Set(b, "Select", &sb).(InsertBuilder)
 }
 
+// Comment adds tags to the trailing comment of the statement.
+//
+// See SelectBuilder.Comment for more information.
+func (b InsertBuilder) Comment(tags map[string]string) InsertBuilder {
+	return builder.Append(b, "Comments", tags).(InsertBuilder)
+}
+
+// Apply calls fn with the builder and returns its result, which allows reusing
+// functions that add clauses to a query.
+func (b InsertBuilder) Apply(fn func(InsertBuilder) InsertBuilder) InsertBuilder {
+	return fn(b)
+}
+
+// ApplyIf calls Apply if cond is true, and returns the builder unchanged
+// otherwise.
+func (b InsertBuilder) ApplyIf(cond bool, fn func(InsertBuilder) InsertBuilder) InsertBuilder {
+	if !cond {
+		return b
+	}
+	return b.Apply(fn)
+}
+
 func (b InsertBuilder) statementKeyword(keyword string) InsertBuilder {
 	return builder.Set(b, "StatementKeyword", keyword).(InsertBuilder)
 }
@@ -93,3 +93,113 @@ func TestInsertBuilderReplace(t *testing.T) {
 
 	assert.Equal(t, expectedSQL, sql)
 }
+
+func TestInsertBuilderApply(t *testing.T) {
+	returning := func(b InsertBuilder) InsertBuilder {
+		return b.Suffix("RETURNING id")
+	}
+
+	sql, _, err := Insert("t").Values(1).ApplyIf(false, returning).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t VALUES (?)", sql)
+
+	sql, _, err = Insert("t").Values(1).Apply(returning).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t VALUES (?) RETURNING id", sql)
+}
+
+func TestInsertBuilderToSQLPretty(t *testing.T) {
+	sql, args, err := Insert("t").Columns("a", "b").Values(1, 2).Values(3, 4).ToSQLPretty("  ")
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t (a,b)\nVALUES (?,?),\n  (?,?)", sql)
+	assert.Equal(t, []interface{}{1, 2, 3, 4}, args)
+
+	sql, _, err = Insert("t").Columns("a").Select(Select("a").From("b").Where("c = 1")).ToSQLPretty("  ")
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t (a)\nSELECT a\nFROM b\nWHERE c = 1", sql)
+}
+
+func TestInsertBuilderDefaultValues(t *testing.T) {
+	sql, args, err := Insert("events").DefaultValues().Suffix("RETURNING id").ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO events DEFAULT VALUES RETURNING id", sql)
+	assert.Empty(t, args)
+
+	sql, _, err = Insert("events").DefaultValues().Dialect(MySQL).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO events () VALUES ()", sql)
+
+	sql, _, err = Insert("events").DefaultValues().ToSQLPretty("  ")
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO events\nDEFAULT VALUES", sql)
+
+	_, _, err = Insert("events").DefaultValues().Values(1).ToSQL()
+	assert.Error(t, err)
+	_, _, err = Insert("events").DefaultValues().Columns("a").ToSQL()
+	assert.Error(t, err)
+	_, _, err = Insert("events").DefaultValues().Select(Select("1")).ToSQL()
+	assert.Error(t, err)
+}
+
+func TestInsertBuilderDefault(t *testing.T) {
+	sql, args, err := Insert("users").
+		Columns("name", "role", "created_at").
+		Values("moe", Default, Default).
+		Values("larry", "admin", Default).
+		PlaceholderFormat(Dollar).
+		ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (name,role,created_at) VALUES ($1,DEFAULT,DEFAULT),($2,$3,DEFAULT)", sql)
+	assert.Equal(t, []interface{}{"moe", "larry", "admin"}, args)
+
+	sql, args, err = Insert("users").SetMap(map[string]interface{}{"name": "moe", "role": Default}).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (name,role) VALUES (?,DEFAULT)", sql)
+	assert.Equal(t, []interface{}{"moe"}, args)
+}
+
+func TestInsertBuilderSetMaps(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"name": "moe", "age": 13},
+		{"name": "larry", "email": "larry@example.com"},
+		{"age": 14, "name": "curly", "email": Expr("LOWER(?)", "CURLY@EXAMPLE.COM")},
+	}
+
+	sql, args, err := Insert("users").SetMaps(rows).PlaceholderFormat(Dollar).ToSQL()
+	assert.NoError(t, err)
+	expectedSQL := "INSERT INTO users (age,email,name) " +
+		"VALUES ($1,DEFAULT,$2),(DEFAULT,$3,$4),($5,LOWER($6),$7)"
+	assert.Equal(t, expectedSQL, sql)
+	expectedArgs := []interface{}{13, "moe", "larry@example.com", "larry", 14, "CURLY@EXAMPLE.COM", "curly"}
+	assert.Equal(t, expectedArgs, args)
+
+	sql, _, err = Insert("users").SetMaps(rows).MissingAsNull().ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (age,email,name) VALUES (?,NULL,?),(NULL,?,?),(?,LOWER(?),?)", sql)
+
+	_, _, err = Insert("users").SetMaps(rows).StrictMaps().ToSQL()
+	assert.EqualError(t, err, "row 0 of SetMaps is missing column email")
+
+	sql, _, err = Insert("users").SetMaps(rows[2:]).StrictMaps().ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (age,email,name) VALUES (?,LOWER(?),?)", sql)
+}
+
+func TestInsertBuilderSetMapsReset(t *testing.T) {
+	rows := []map[string]interface{}{{"a": 1}, {"b": 2}}
+
+	sql, _, err := Insert("t").Columns("x").Values(1).SetMaps(rows).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t (a,b) VALUES (?,DEFAULT),(DEFAULT,?)", sql)
+
+	sql, _, err = Insert("t").SetMaps(rows).SetMap(map[string]interface{}{"c": 3}).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t (c) VALUES (?)", sql)
+
+	_, _, err = Insert("t").SetMaps(rows).Values(3, 4).ToSQL()
+	assert.Error(t, err)
+	_, _, err = Insert("t").SetMaps([]map[string]interface{}{{}}).ToSQL()
+	assert.Error(t, err)
+	_, _, err = Insert("t").SetMaps(nil).ToSQL()
+	assert.Error(t, err)
+}
@@ -0,0 +1,303 @@
+package sq
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+// joinExpr is a JOIN clause built from its parts rather than from a string.
+type joinExpr struct {
+	// kind is the join keyword, e.g. "LEFT JOIN" or "CROSS APPLY".
+	kind    string
+	lateral bool
+	// table is the name of the joined table, or a SQLizer such as a subquery.
+	table interface{}
+	alias string
+	// hints are index or table hints following the table.
+	hints []string
+	// on is the join condition, which defaults to TRUE for the LATERAL joins
+	// requiring one.
+	on    SQLizer
+	using []string
+	// scopes are the predicates of the scopes of table ANDed into on. The
+	// other outer joins filter table in a scopedTable instead.
+	scopes []SQLizer
+	caller callSite
+}
+
+func newJoinExpr(kind string, lateral bool, table interface{}, alias string, on SQLizer) *joinExpr {
+	j := &joinExpr{kind: kind, lateral: lateral, table: table, alias: alias, on: on}
+	j.caller.capture(1)
+	return j
+}
+
+func (j *joinExpr) site() *callSite {
+	return &j.caller
+}
+
+func (j *joinExpr) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(j)
+}
+
+func (j *joinExpr) writeSQL(w *sqlWriter) {
+	if j.table == nil {
+		w.Fail(fmt.Errorf("%s requires a table or subquery", j.kind))
+		return
+	}
+
+	w.WriteString(j.kind)
+	w.WriteByte(' ')
+	if j.lateral {
+		w.WriteString("LATERAL ")
+	}
+	switch table := j.table.(type) {
+	case string:
+		w.WriteString(table)
+	case SQLizer:
+		if !isStatement(table) {
+			w.WriteSQL(table)
+			break
+		}
+		if j.alias == "" {
+			w.Fail(fmt.Errorf("%s of a subquery requires an alias", j.kind))
+			return
+		}
+		w.WriteSubquery(table)
+	default:
+		w.Fail(fmt.Errorf("%s expected a table name or SQLizer, not %T", j.kind, j.table))
+		return
+	}
+	if j.alias != "" {
+		w.WriteString(" AS ")
+		w.WriteString(j.alias)
+	}
+	for _, hint := range j.hints {
+		w.WriteByte(' ')
+		w.WriteString(hint)
+	}
+
+	switch {
+	case len(j.using) > 0:
+		w.WriteString(" USING (")
+		w.WriteString(strings.Join(j.using, ", "))
+		w.WriteByte(')')
+	case j.on != nil && len(j.scopes) > 0:
+		w.WriteString(" ON (")
+		w.WriteSQL(j.on)
+		w.WriteByte(')')
+		for _, pred := range j.scopes {
+			w.WriteString(" AND ")
+			w.WriteSQL(pred)
+		}
+	case j.on != nil:
+		w.WriteString(" ON ")
+		w.WriteSQL(j.on)
+	case j.needsOn() && j.lateral:
+		w.WriteString(" ON TRUE")
+	case j.needsOn():
+		// a missing condition is more likely a mistake than a cross join
+		w.Fail(fmt.Errorf("%s requires an ON condition; use CROSS JOIN to join every row", j.kind))
+	}
+}
+
+// needsOn reports whether the join requires an ON condition.
+func (j *joinExpr) needsOn() bool {
+	switch j.kind {
+	case "CROSS JOIN", "CROSS APPLY", "OUTER APPLY", "NATURAL JOIN", "NATURAL LEFT JOIN":
+		return false
+	}
+	return true
+}
+
+// outer reports whether the join is an outer join.
+func (j *joinExpr) outer() bool {
+	switch j.kind {
+	case "LEFT JOIN", "RIGHT JOIN", "FULL OUTER JOIN", "NATURAL LEFT JOIN", "OUTER APPLY":
+		return true
+	}
+	return false
+}
+
+// scope returns j with the scopes of its table applied, like scopeJoin.
+func (j *joinExpr) scope(scopes []scope) (SQLizer, []SQLizer) {
+	table, ok := j.table.(string)
+	if !ok {
+		return j, nil
+	}
+	ref := tableRef{table: table, ref: table}
+	if j.alias != "" {
+		ref.ref = j.alias
+	}
+	preds := scopesFor(scopes, []tableRef{ref})
+	if len(preds) == 0 {
+		return j, nil
+	}
+	if !j.outer() {
+		return j, preds
+	}
+	scoped := *j
+	if j.on == nil || j.kind == "RIGHT JOIN" || j.kind == "FULL OUTER JOIN" {
+		// filter the table itself, hints included, under the name it is
+		// referred to by, since ON doesn't filter the preserved table of a
+		// RIGHT or FULL join
+		target := table
+		if j.alias != "" {
+			target += " AS " + j.alias
+		}
+		for _, hint := range j.hints {
+			target += " " + hint
+		}
+		scoped.table = scopedTable{table: target, preds: preds}
+		scoped.alias = ref.ref
+		scoped.hints = nil
+		return &scoped, nil
+	}
+	scoped.scopes = preds
+	return &scoped, nil
+}
+
+// withHint returns a copy of j with hint added after its table.
+func (j *joinExpr) withHint(hint string) *joinExpr {
+	hinted := *j
+	hinted.hints = append(j.hints[:len(j.hints):len(j.hints)], hint)
+	return &hinted
+}
+
+// JoinSelect adds a JOIN of the subquery sb, aliased as alias, to the query.
+// The placeholders of sb are numbered along with the rest of the query. on
+// must not be nil.
+//
+// Ex:
+//
+//	counts := Select("user_id", "COUNT(*) AS n").From("posts").Where("published").GroupBy("user_id")
+//	Select("u.name", "c.n").From("users u").JoinSelect(counts, "c", Expr("c.user_id = u.id"))
+//	// SELECT u.name, c.n FROM users u JOIN (SELECT ...) AS c ON c.user_id = u.id
+func (b SelectBuilder) JoinSelect(sb SelectBuilder, alias string, on SQLizer) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("JOIN", false, sb, alias, on)).(SelectBuilder)
+}
+
+// LeftJoinSelect adds a LEFT JOIN of the subquery sb, aliased as alias, to the
+// query.
+func (b SelectBuilder) LeftJoinSelect(sb SelectBuilder, alias string, on SQLizer) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("LEFT JOIN", false, sb, alias, on)).(SelectBuilder)
+}
+
+// JoinLateral adds a JOIN LATERAL of the subquery sb, which can refer to the
+// tables preceding it, to the query. The condition defaults to ON TRUE if on is
+// nil.
+//
+// Ex:
+//
+//	latest := Select("*").From("posts p").Where("p.user_id = u.id").OrderBy("p.created_at DESC").Limit(3)
+//	Select("u.name", "l.title").From("users u").JoinLateral(latest, "l", nil)
+//	// SELECT u.name, l.title FROM users u JOIN LATERAL (SELECT ...) AS l ON TRUE
+func (b SelectBuilder) JoinLateral(sb SelectBuilder, alias string, on SQLizer) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("JOIN", true, sb, alias, on)).(SelectBuilder)
+}
+
+// LeftJoinLateral adds a LEFT JOIN LATERAL of the subquery sb to the query. The
+// condition defaults to ON TRUE if on is nil.
+func (b SelectBuilder) LeftJoinLateral(sb SelectBuilder, alias string, on SQLizer) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("LEFT JOIN", true, sb, alias, on)).(SelectBuilder)
+}
+
+// CrossJoinLateral adds a CROSS JOIN LATERAL of the subquery sb to the query.
+func (b SelectBuilder) CrossJoinLateral(sb SelectBuilder, alias string) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("CROSS JOIN", true, sb, alias, nil)).(SelectBuilder)
+}
+
+// CrossApply adds a SQL Server CROSS APPLY of the subquery sb to the query,
+// which is the equivalent of CROSS JOIN LATERAL.
+func (b SelectBuilder) CrossApply(sb SelectBuilder, alias string) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("CROSS APPLY", false, sb, alias, nil)).(SelectBuilder)
+}
+
+// OuterApply adds a SQL Server OUTER APPLY of the subquery sb to the query,
+// which is the equivalent of LEFT JOIN LATERAL ... ON TRUE.
+func (b SelectBuilder) OuterApply(sb SelectBuilder, alias string) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("OUTER APPLY", false, sb, alias, nil)).(SelectBuilder)
+}
+
+// JoinOn adds a JOIN of table, aliased as alias unless it is empty, to the
+// query. The condition on can be any predicate, with its args numbered along
+// with the rest of the query, and must not be nil.
+//
+// Ex:
+//
+//	Select("*").From("users u").JoinOn("orgs", "o", And{Expr("o.id = u.org_id"), Eq{"o.kind": "a"}})
+//	// SELECT * FROM users u JOIN orgs AS o ON (o.id = u.org_id AND o.kind = ?)
+func (b SelectBuilder) JoinOn(table, alias string, on SQLizer) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("JOIN", false, table, alias, on)).(SelectBuilder)
+}
+
+// LeftJoinOn adds a LEFT JOIN of table to the query. See JoinOn.
+func (b SelectBuilder) LeftJoinOn(table, alias string, on SQLizer) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("LEFT JOIN", false, table, alias, on)).(SelectBuilder)
+}
+
+// RightJoinOn adds a RIGHT JOIN of table to the query. See JoinOn.
+func (b SelectBuilder) RightJoinOn(table, alias string, on SQLizer) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("RIGHT JOIN", false, table, alias, on)).(SelectBuilder)
+}
+
+// InnerJoinOn adds an INNER JOIN of table to the query. See JoinOn.
+func (b SelectBuilder) InnerJoinOn(table, alias string, on SQLizer) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("INNER JOIN", false, table, alias, on)).(SelectBuilder)
+}
+
+// FullJoinOn adds a FULL OUTER JOIN of table to the query. See JoinOn.
+func (b SelectBuilder) FullJoinOn(table, alias string, on SQLizer) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("FULL OUTER JOIN", false, table, alias, on)).(SelectBuilder)
+}
+
+// JoinUsing adds a JOIN of table on the given columns to the query.
+//
+// Ex:
+//
+//	Select("*").From("users").JoinUsing("profiles", "user_id")
+//	// SELECT * FROM users JOIN profiles USING (user_id)
+func (b SelectBuilder) JoinUsing(table string, columns ...string) SelectBuilder {
+	return b.joinUsing("JOIN", table, columns)
+}
+
+// LeftJoinUsing adds a LEFT JOIN of table on the given columns to the query.
+func (b SelectBuilder) LeftJoinUsing(table string, columns ...string) SelectBuilder {
+	return b.joinUsing("LEFT JOIN", table, columns)
+}
+
+// RightJoinUsing adds a RIGHT JOIN of table on the given columns to the query.
+func (b SelectBuilder) RightJoinUsing(table string, columns ...string) SelectBuilder {
+	return b.joinUsing("RIGHT JOIN", table, columns)
+}
+
+// InnerJoinUsing adds an INNER JOIN of table on the given columns to the query.
+func (b SelectBuilder) InnerJoinUsing(table string, columns ...string) SelectBuilder {
+	return b.joinUsing("INNER JOIN", table, columns)
+}
+
+// FullJoinUsing adds a FULL OUTER JOIN of table on the given columns to the
+// query.
+func (b SelectBuilder) FullJoinUsing(table string, columns ...string) SelectBuilder {
+	return b.joinUsing("FULL OUTER JOIN", table, columns)
+}
+
+func (b SelectBuilder) joinUsing(kind, table string, columns []string) SelectBuilder {
+	j := &joinExpr{kind: kind, table: table, using: columns}
+	j.caller.capture(1)
+	if len(columns) == 0 {
+		j.table = errorSQLizer{fmt.Errorf("%s USING requires at least one column", kind)}
+	}
+	return builder.Append(b, "Joins", j).(SelectBuilder)
+}
+
+// NaturalJoin adds a NATURAL JOIN of table to the query.
+func (b SelectBuilder) NaturalJoin(table string) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("NATURAL JOIN", false, table, "", nil)).(SelectBuilder)
+}
+
+// NaturalLeftJoin adds a NATURAL LEFT JOIN of table to the query.
+func (b SelectBuilder) NaturalLeftJoin(table string) SelectBuilder {
+	return builder.Append(b, "Joins", newJoinExpr("NATURAL LEFT JOIN", false, table, "", nil)).(SelectBuilder)
+}
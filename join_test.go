@@ -0,0 +1,248 @@
+package sq
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinSelect(t *testing.T) {
+	counts := Select("user_id", "COUNT(*) AS n").From("posts").Where("published = ?", true).GroupBy("user_id")
+
+	b := Select("u.name", "c.n").
+		From("users u").
+		Where("u.org_id = ?", 1).
+		JoinSelect(counts, "c", Expr("c.user_id = u.id AND c.n > ?", 5)).
+		LeftJoinSelect(Select("id").From("teams").Where("kind = ?", "a"), "t", Expr("t.id = u.team_id")).
+		Where("u.active = ?", true).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSQL()
+	assert.NoError(t, err)
+
+	expectedSQL := "SELECT u.name, c.n FROM users u " +
+		"JOIN (SELECT user_id, COUNT(*) AS n FROM posts WHERE published = $1 GROUP BY user_id) AS c ON c.user_id = u.id AND c.n > $2 " +
+		"LEFT JOIN (SELECT id FROM teams WHERE kind = $3) AS t ON t.id = u.team_id " +
+		"WHERE u.org_id = $4 AND u.active = $5"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{true, 5, "a", 1, true}, args)
+}
+
+func TestJoinLateral(t *testing.T) {
+	latest := Select("p.title").
+		From("posts p").
+		Where("p.user_id = u.id AND p.kind = ?", "blog").
+		OrderBy("p.created_at DESC").
+		Limit(3)
+
+	tests := []struct {
+		b   SelectBuilder
+		sql string
+	}{
+		{
+			Select("u.name", "l.title").From("users u").JoinLateral(latest, "l", nil),
+			"SELECT u.name, l.title FROM users u JOIN LATERAL (" +
+				"SELECT p.title FROM posts p WHERE p.user_id = u.id AND p.kind = ? ORDER BY p.created_at DESC LIMIT 3" +
+				") AS l ON TRUE",
+		},
+		{
+			Select("u.name", "l.title").From("users u").LeftJoinLateral(latest, "l", Expr("l.title <> ?", "")),
+			"SELECT u.name, l.title FROM users u LEFT JOIN LATERAL (" +
+				"SELECT p.title FROM posts p WHERE p.user_id = u.id AND p.kind = ? ORDER BY p.created_at DESC LIMIT 3" +
+				") AS l ON l.title <> ?",
+		},
+		{
+			Select("u.name", "l.title").From("users u").CrossJoinLateral(latest, "l"),
+			"SELECT u.name, l.title FROM users u CROSS JOIN LATERAL (" +
+				"SELECT p.title FROM posts p WHERE p.user_id = u.id AND p.kind = ? ORDER BY p.created_at DESC LIMIT 3" +
+				") AS l",
+		},
+		{
+			Select("u.name", "l.title").From("users u").CrossApply(latest, "l"),
+			"SELECT u.name, l.title FROM users u CROSS APPLY (" +
+				"SELECT p.title FROM posts p WHERE p.user_id = u.id AND p.kind = ? ORDER BY p.created_at DESC LIMIT 3" +
+				") AS l",
+		},
+		{
+			Select("u.name", "l.title").From("users u").OuterApply(latest, "l"),
+			"SELECT u.name, l.title FROM users u OUTER APPLY (" +
+				"SELECT p.title FROM posts p WHERE p.user_id = u.id AND p.kind = ? ORDER BY p.created_at DESC LIMIT 3" +
+				") AS l",
+		},
+	}
+	for _, test := range tests {
+		sql, args, err := test.b.ToSQL()
+		assert.NoError(t, err)
+		assert.Equal(t, test.sql, sql)
+		assert.Equal(t, "blog", args[0])
+	}
+}
+
+func TestJoinSelectErrors(t *testing.T) {
+	_, _, err := Select("*").From("users").JoinSelect(Select("id").From("x"), "", nil).ToSQL()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").From("users").JoinSelect(Select(), "x", nil).ToSQL()
+	assert.Error(t, err)
+
+	// only LATERAL joins default to ON TRUE
+	tests := []SelectBuilder{
+		Select("*").From("users").JoinSelect(Select("id").From("x"), "x", nil),
+		Select("*").From("users").LeftJoinSelect(Select("id").From("x"), "x", nil),
+		Select("*").From("users").JoinOn("orgs", "o", nil),
+		Select("*").From("users").LeftJoinOn("orgs", "o", nil),
+		Select("*").From("users").FullJoinOn("orgs", "o", nil),
+	}
+	for _, b := range tests {
+		_, _, err = b.ToSQL()
+		assert.Error(t, err)
+	}
+}
+
+func TestJoinSelectPretty(t *testing.T) {
+	sub := Select("id").From("teams").Where("kind = ?", "a")
+	sql, _, err := Select("*").From("users u").LeftJoinLateral(sub, "t", nil).ToSQLPretty("  ")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT *\nFROM users u\nLEFT JOIN LATERAL (\n  SELECT id\n  FROM teams\n  WHERE kind = ?\n) AS t ON TRUE", sql)
+}
+
+func TestJoinSelectJSON(t *testing.T) {
+	sub := Select("id").From("teams").Where(Eq{"kind": "a"})
+	b := Select("*").From("users u").JoinSelect(sub, "t", Eq{"t.id": 1}).OuterApply(sub, "o")
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+
+	var decoded SelectBuilder
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+
+	expectedSQL, expectedArgs, err := b.ToSQL()
+	assert.NoError(t, err)
+	sql, args, err := decoded.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{"a", int64(1), "a"}, args)
+	assert.Len(t, expectedArgs, 3)
+
+	doc := `{"version":1,"columns":["*"],"from":"users","joins":[{"join":{"kind":"JOIN; DROP","table":"x"}}]}`
+	assert.Error(t, json.Unmarshal([]byte(doc), &decoded))
+}
+
+func TestJoinOn(t *testing.T) {
+	b := Select("*").
+		From("users u").
+		Where("u.active = ?", true).
+		JoinOn("orgs", "o", And{Expr("o.id = u.org_id"), Eq{"o.kind": "a"}}).
+		LeftJoinOn("teams", "t", Expr("t.id = u.team_id AND t.size > ?", 3)).
+		RightJoinOn("roles", "", Expr("roles.user_id = u.id")).
+		InnerJoinOn("groups", "g", Expr("g.id = u.group_id")).
+		FullJoinOn("badges", "b", Expr("b.user_id = u.id")).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSQL()
+	assert.NoError(t, err)
+
+	expectedSQL := "SELECT * FROM users u " +
+		"JOIN orgs AS o ON (o.id = u.org_id AND o.kind = $1) " +
+		"LEFT JOIN teams AS t ON t.id = u.team_id AND t.size > $2 " +
+		"RIGHT JOIN roles ON roles.user_id = u.id " +
+		"INNER JOIN groups AS g ON g.id = u.group_id " +
+		"FULL OUTER JOIN badges AS b ON b.user_id = u.id " +
+		"WHERE u.active = $3"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{"a", 3, true}, args)
+}
+
+func TestJoinUsing(t *testing.T) {
+	b := Select("*").
+		From("users").
+		JoinUsing("profiles", "user_id").
+		LeftJoinUsing("settings", "user_id", "org_id").
+		RightJoinUsing("a", "x").
+		InnerJoinUsing("b", "y").
+		FullJoinUsing("c", "z").
+		NaturalJoin("d").
+		NaturalLeftJoin("e").
+		FullJoin("f ON f.id = users.f_id")
+
+	sql, _, err := b.ToSQL()
+	assert.NoError(t, err)
+
+	expectedSQL := "SELECT * FROM users " +
+		"JOIN profiles USING (user_id) " +
+		"LEFT JOIN settings USING (user_id, org_id) " +
+		"RIGHT JOIN a USING (x) " +
+		"INNER JOIN b USING (y) " +
+		"FULL OUTER JOIN c USING (z) " +
+		"NATURAL JOIN d " +
+		"NATURAL LEFT JOIN e " +
+		"FULL OUTER JOIN f ON f.id = users.f_id"
+	assert.Equal(t, expectedSQL, sql)
+
+	_, _, err = Select("*").From("users").JoinUsing("profiles").ToSQL()
+	assert.Error(t, err)
+}
+
+func TestJoinOnScopesAndHints(t *testing.T) {
+	sb := StatementBuilder.Scope("orgs", Eq{"deleted_at": nil})
+
+	sql, _, err := sb.Select("*").
+		From("users u").
+		LeftJoinOn("orgs", "o", Expr("o.id = u.org_id")).
+		JoinHint(UseIndex("PRIMARY")).
+		ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users u LEFT JOIN orgs AS o USE INDEX (PRIMARY) ON (o.id = u.org_id) AND o.deleted_at IS NULL", sql)
+
+	sql, _, err = sb.Select("*").From("users u").JoinUsing("orgs", "org_id").ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users u JOIN orgs USING (org_id) WHERE orgs.deleted_at IS NULL", sql)
+
+	// outer joins without an ON condition join the scoped rows of the table
+	sql, _, err = sb.Select("*").
+		From("users u").
+		LeftJoinUsing("orgs", "org_id").
+		JoinHint(TableHint("NOLOCK")).
+		ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users u LEFT JOIN (SELECT * FROM orgs WITH (NOLOCK) WHERE orgs.deleted_at IS NULL) AS orgs USING (org_id)", sql)
+
+	sql, _, err = sb.Select("*").From("users u").NaturalLeftJoin("orgs").ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users u NATURAL LEFT JOIN (SELECT * FROM orgs WHERE orgs.deleted_at IS NULL) AS orgs", sql)
+
+	sql, args, err := StatementBuilder.Scope("profiles", Eq{"tenant_id": 7}).
+		Select("*").
+		From("users").
+		FullJoinUsing("profiles", "user_id").
+		ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users FULL OUTER JOIN (SELECT * FROM profiles WHERE profiles.tenant_id = ?) AS profiles USING (user_id)", sql)
+	assert.Equal(t, []interface{}{7}, args)
+}
+
+func TestJoinOnJSON(t *testing.T) {
+	b := Select("*").
+		From("users u").
+		JoinOn("orgs", "o", Eq{"o.kind": "a"}).
+		JoinHint(ForceIndex("PRIMARY")).
+		LeftJoinUsing("teams", "team_id").
+		NaturalJoin("profiles")
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+
+	var decoded SelectBuilder
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+
+	expectedSQL, _, err := b.ToSQL()
+	assert.NoError(t, err)
+	sql, args, err := decoded.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{"a"}, args)
+
+	doc := `{"version":1,"columns":["*"],"from":"users","joins":[{"join":{"kind":"JOIN","table":"x","using":["id) OR (1"]}}]}`
+	assert.Error(t, json.Unmarshal([]byte(doc), &decoded))
+}
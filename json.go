@@ -0,0 +1,1212 @@
+package sq
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+// jsonVersion is the version of the JSON schema produced by MarshalJSON.
+const jsonVersion = 1
+
+// ErrRawSQL is returned when decoding JSON containing a raw SQL fragment
+// without JSONDecoder.AllowRaw.
+var ErrRawSQL = errors.New("raw SQL is not allowed")
+
+// JSONDecoder decodes queries and predicates from the JSON produced by their
+// MarshalJSON methods. The UnmarshalJSON methods use a zero JSONDecoder.
+//
+// Strings in the JSON are SQL fragments. Unless AllowRaw is set, the only ones
+// accepted are identifiers where the query expects them: column names (with
+// an optional alias) in columns, function arguments and predicate keys, table
+// names (with an optional alias) in FROM, column names followed by ASC or
+// DESC in ORDER BY, and index and optimizer hints taking only identifiers and
+// numbers. Expr, prefixes, suffixes and joins given as strings are always raw.
+type JSONDecoder struct {
+	// AllowRaw allows raw SQL fragments, which must never be set for JSON
+	// coming from an untrusted source.
+	AllowRaw bool
+	// AllowUnscoped allows queries bypassing the scopes of StatementBuilder,
+	// which AllowRaw also does.
+	AllowUnscoped bool
+	// StatementBuilder is what decoded queries are built from, e.g. to apply
+	// its scopes. It defaults to StatementBuilder.
+	StatementBuilder StatementBuilderType
+}
+
+// selectJSON is the JSON representation of a SelectBuilder.
+type selectJSON struct {
+	Version           int               `json:"version,omitempty"`
+	PlaceholderFormat string            `json:"placeholderFormat,omitempty"`
+	Dialect           string            `json:"dialect,omitempty"`
+	Prefixes          []json.RawMessage `json:"prefixes,omitempty"`
+	Hints             []string          `json:"hints,omitempty"`
+	Options           []string          `json:"options,omitempty"`
+	DistinctOn        []json.RawMessage `json:"distinctOn,omitempty"`
+	Columns           []json.RawMessage `json:"columns,omitempty"`
+	From              json.RawMessage   `json:"from,omitempty"`
+	FromHints         []string          `json:"fromHints,omitempty"`
+	Joins             []json.RawMessage `json:"joins,omitempty"`
+	Where             []json.RawMessage `json:"where,omitempty"`
+	GroupBy           []json.RawMessage `json:"groupBy,omitempty"`
+	WithRollup        bool              `json:"withRollup,omitempty"`
+	Having            []json.RawMessage `json:"having,omitempty"`
+	OrderBy           []json.RawMessage `json:"orderBy,omitempty"`
+	Limit             json.RawMessage   `json:"limit,omitempty"`
+	Offset            json.RawMessage   `json:"offset,omitempty"`
+	BindLimits        bool              `json:"bindLimits,omitempty"`
+	Pagination        string            `json:"pagination,omitempty"`
+	LimitPercent      bool              `json:"limitPercent,omitempty"`
+	LimitWithTies     bool              `json:"limitWithTies,omitempty"`
+	Suffixes          []json.RawMessage `json:"suffixes,omitempty"`
+	Unscoped          bool              `json:"unscoped,omitempty"`
+}
+
+// Encoding
+
+// MarshalJSON encodes the query as JSON which can be decoded back into a
+// SelectBuilder by UnmarshalJSON or JSONDecoder.DecodeSelect.
+//
+// Only the predicates of this package, Expr, Alias, the function helpers and
+// nested SelectBuilders can be encoded.
+func (b SelectBuilder) MarshalJSON() ([]byte, error) {
+	v, err := marshalSelect(b)
+	if err != nil {
+		return nil, err
+	}
+	v.Version = jsonVersion
+	return json.Marshal(v)
+}
+
+func marshalSelect(b SelectBuilder) (*selectJSON, error) {
+	d := builder.GetStruct(b).(selectData)
+	v := &selectJSON{
+		Hints:         d.Hints,
+		Options:       d.Options,
+		FromHints:     d.FromHints,
+		WithRollup:    d.WithRollup,
+		BindLimits:    d.BindLimits,
+		LimitPercent:  d.LimitPercent,
+		LimitWithTies: d.LimitWithTies,
+		Unscoped:      d.Unscoped,
+	}
+
+	var err error
+	if d.PlaceholderFormat != nil {
+		if v.PlaceholderFormat, err = placeholderFormatName(d.PlaceholderFormat); err != nil {
+			return nil, err
+		}
+	}
+	if d.From != nil {
+		if v.From, err = marshalRaw(d.From); err != nil {
+			return nil, err
+		}
+	}
+	for _, clause := range []struct {
+		dst   *[]json.RawMessage
+		parts []SQLizer
+	}{
+		{&v.Prefixes, d.Prefixes},
+		{&v.DistinctOn, d.DistinctOn},
+		{&v.Columns, d.Columns},
+		{&v.Joins, d.Joins},
+		{&v.Where, d.WhereParts},
+		{&v.GroupBy, d.GroupBys},
+		{&v.Having, d.HavingParts},
+		{&v.OrderBy, d.OrderByParts},
+		{&v.Suffixes, d.Suffixes},
+	} {
+		for _, p := range clause.parts {
+			raw, err := marshalRaw(p)
+			if err != nil {
+				return nil, err
+			}
+			*clause.dst = append(*clause.dst, raw)
+		}
+	}
+	if d.Limit != nil {
+		if v.Limit, err = marshalCount(d.Limit); err != nil {
+			return nil, err
+		}
+	}
+	if d.Offset != nil {
+		if v.Offset, err = marshalCount(d.Offset); err != nil {
+			return nil, err
+		}
+	}
+	// the dialect and the pagination are only encoded if they were set, so
+	// that they default to the ones of the decoder's StatementBuilder
+	if _, ok := builder.Get(b, "Dialect"); ok {
+		if v.Dialect, err = dialectName(d.Dialect); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := builder.Get(b, "Pagination"); ok {
+		if v.Pagination, err = paginationName(d.Pagination); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func marshalRaw(s interface{}) (json.RawMessage, error) {
+	v, err := marshalExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func tagged(tag string, v interface{}) map[string]interface{} {
+	return map[string]interface{}{tag: v}
+}
+
+// marshalExpr returns the value encoding s as JSON: a string for plain SQL
+// fragments, and an object with a single key naming the type otherwise.
+func marshalExpr(s interface{}) (interface{}, error) {
+	switch e := s.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return e, nil
+	case *part:
+		return marshalPart(e.pred, e.args)
+	case *wherePart:
+		if m, ok := e.pred.(map[string]interface{}); ok {
+			return tagged("eq", m), nil
+		}
+		return marshalPart(e.pred, e.args)
+	case Eq:
+		return tagged("eq", map[string]interface{}(e)), nil
+	case NotEq:
+		return tagged("neq", map[string]interface{}(e)), nil
+	case Lt:
+		return tagged("lt", map[string]interface{}(e)), nil
+	case LtOrEq:
+		return tagged("lte", map[string]interface{}(e)), nil
+	case Gt:
+		return tagged("gt", map[string]interface{}(e)), nil
+	case GtOrEq:
+		return tagged("gte", map[string]interface{}(e)), nil
+	case Like:
+		return tagged("like", map[string]interface{}(e)), nil
+	case NotLike:
+		return tagged("nlike", map[string]interface{}(e)), nil
+	case ILike:
+		return tagged("ilike", map[string]interface{}(e)), nil
+	case NotILike:
+		return tagged("nilike", map[string]interface{}(e)), nil
+	case And:
+		list, err := marshalList(e)
+		return tagged("and", list), err
+	case Or:
+		list, err := marshalList(e)
+		return tagged("or", list), err
+	case expr:
+		args, err := marshalArgs(e.args)
+		return tagged("expr", map[string]interface{}{"sql": e.sql, "args": args}), err
+	case aliasExpr:
+		v, err := marshalExpr(e.expr)
+		return tagged("alias", map[string]interface{}{"expr": v, "as": e.alias}), err
+	case funcExpr:
+		args := make([]interface{}, len(e.args))
+		for i, arg := range e.args {
+			v, err := marshalExpr(arg)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		return tagged("func", map[string]interface{}{"name": e.name, "distinct": e.distinct, "args": args}), nil
+	case castExpr:
+		v, err := marshalExpr(e.expr)
+		return tagged("cast", map[string]interface{}{"expr": v, "type": e.typ}), err
+	case orderExpr:
+		v, err := marshalExpr(e.expr)
+		if err != nil {
+			return nil, err
+		}
+		m := map[string]interface{}{"expr": v, "order": "asc"}
+		if e.order == Desc {
+			m["order"] = "desc"
+		}
+		switch e.nulls {
+		case NullsFirst:
+			m["nulls"] = "first"
+		case NullsLast:
+			m["nulls"] = "last"
+		}
+		return tagged("order", m), nil
+	case SelectBuilder:
+		v, err := marshalSelect(e)
+		return tagged("select", v), err
+	case *joinExpr:
+		table, err := marshalExpr(e.table)
+		if err != nil {
+			return nil, err
+		}
+		on, err := marshalExpr(e.on)
+		return tagged("join", map[string]interface{}{
+			"kind": e.kind, "lateral": e.lateral, "table": table, "alias": e.alias,
+			"hints": e.hints, "on": on, "using": e.using,
+		}), err
+	default:
+		return nil, fmt.Errorf("sq: cannot encode %T as JSON", s)
+	}
+}
+
+func marshalPart(pred interface{}, args []interface{}) (interface{}, error) {
+	if sql, ok := pred.(string); ok && len(args) > 0 {
+		return marshalExpr(expr{sql: sql, args: args})
+	}
+	return marshalExpr(pred)
+}
+
+func marshalList(list []SQLizer) ([]interface{}, error) {
+	vs := make([]interface{}, len(list))
+	for i, s := range list {
+		v, err := marshalExpr(s)
+		if err != nil {
+			return nil, err
+		}
+		vs[i] = v
+	}
+	return vs, nil
+}
+
+// marshalArgs encodes the args of an Expr. Args which are SQLizers are encoded
+// as objects, which no other arg can be encoded as.
+func marshalArgs(args []interface{}) ([]interface{}, error) {
+	vs := make([]interface{}, len(args))
+	for i, arg := range args {
+		if s, ok := arg.(SQLizer); ok {
+			v, err := marshalExpr(s)
+			if err != nil {
+				return nil, err
+			}
+			vs[i] = v
+		} else {
+			vs[i] = arg
+		}
+	}
+	return vs, nil
+}
+
+func placeholderFormatName(f PlaceholderFormat) (string, error) {
+	switch f.(type) {
+	case questionFormat:
+		return "question", nil
+	case dollarFormat:
+		return "dollar", nil
+	case colonFormat:
+		return "colon", nil
+	case atpFormat:
+		return "atp", nil
+	default:
+		return "", fmt.Errorf("sq: cannot encode placeholder format %T as JSON", f)
+	}
+}
+
+func dialectName(d Dialect) (string, error) {
+	switch d {
+	case Postgres:
+		return "postgres", nil
+	case MySQL:
+		return "mysql", nil
+	case SQLite:
+		return "sqlite", nil
+	case SQLServer:
+		return "sqlserver", nil
+	default:
+		return "", fmt.Errorf("sq: cannot encode dialect %s as JSON", d)
+	}
+}
+
+func paginationName(p Pagination) (string, error) {
+	switch p {
+	case LimitOffset:
+		return "limitOffset", nil
+	case OffsetFetch:
+		return "offsetFetch", nil
+	case TopOffsetFetch:
+		return "topOffsetFetch", nil
+	default:
+		return "", fmt.Errorf("sq: cannot encode pagination %s as JSON", p)
+	}
+}
+
+// marshalCount encodes the value of a LIMIT or OFFSET clause, which is either
+// a number or an expression.
+func marshalCount(v interface{}) (json.RawMessage, error) {
+	if n, ok := v.(uint64); ok {
+		return json.RawMessage(strconv.FormatUint(n, 10)), nil
+	}
+	return marshalRaw(v)
+}
+
+// Decoding
+
+// UnmarshalJSON decodes a query encoded by MarshalJSON, rejecting raw SQL.
+// See JSONDecoder.
+func (b *SelectBuilder) UnmarshalJSON(data []byte) error {
+	sb, err := JSONDecoder{}.DecodeSelect(data)
+	if err != nil {
+		return err
+	}
+	*b = sb
+	return nil
+}
+
+// DecodeSelect decodes a query encoded by SelectBuilder.MarshalJSON.
+func (d JSONDecoder) DecodeSelect(data []byte) (SelectBuilder, error) {
+	var v selectJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return SelectBuilder{}, err
+	}
+	if v.Version != jsonVersion {
+		return SelectBuilder{}, fmt.Errorf("sq: unsupported JSON version %d", v.Version)
+	}
+	return d.decodeSelect(&v)
+}
+
+// DecodePredicate decodes a predicate or expression encoded by the MarshalJSON
+// method of one of the predicates of this package.
+func (d JSONDecoder) DecodePredicate(data []byte) (SQLizer, error) {
+	v, err := d.decodeExpr(data, fragmentPredicate)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := v.(string); ok {
+		return Expr(s), nil
+	}
+	return v.(SQLizer), nil
+}
+
+func (d JSONDecoder) statementBuilder() StatementBuilderType {
+	if d.StatementBuilder == (StatementBuilderType{}) {
+		return StatementBuilder
+	}
+	return d.StatementBuilder
+}
+
+func (d JSONDecoder) decodeSelect(v *selectJSON) (SelectBuilder, error) {
+	b := d.statementBuilder().Select()
+
+	if v.PlaceholderFormat != "" {
+		f, err := placeholderFormatByName(v.PlaceholderFormat)
+		if err != nil {
+			return b, err
+		}
+		b = b.PlaceholderFormat(f)
+	}
+	if v.Dialect != "" {
+		dialect, err := dialectByName(v.Dialect)
+		if err != nil {
+			return b, err
+		}
+		b = b.Dialect(dialect)
+	}
+
+	for _, hint := range v.Hints {
+		if !d.AllowRaw && !hintPattern.MatchString(hint) {
+			return b, rawSQLError(hint)
+		}
+		b = b.Hint(hint)
+	}
+
+	for _, option := range v.Options {
+		if !d.AllowRaw && !keywordPattern.MatchString(option) {
+			return b, rawSQLError(option)
+		}
+	}
+	if len(v.Options) > 0 {
+		b = b.Options(v.Options...)
+	}
+
+	for _, raw := range v.Prefixes {
+		e, err := d.decodeSQLizer(raw, fragmentRaw)
+		if err != nil {
+			return b, err
+		}
+		b = b.PrefixExpr(e)
+	}
+
+	for _, raw := range v.DistinctOn {
+		e, err := d.decodeExpr(raw, fragmentGroupBy)
+		if err != nil {
+			return b, err
+		}
+		b = b.DistinctOn(e)
+	}
+
+	for _, raw := range v.Columns {
+		e, err := d.decodeExpr(raw, fragmentColumn)
+		if err != nil {
+			return b, err
+		}
+		b = b.Column(e)
+	}
+
+	if len(v.From) > 0 {
+		e, err := d.decodeExpr(v.From, fragmentTable)
+		if err != nil {
+			return b, err
+		}
+		switch from := e.(type) {
+		case string:
+			b = b.From(from)
+		case aliasExpr:
+			sub, ok := from.expr.(SelectBuilder)
+			if !ok {
+				return b, fmt.Errorf("sq: cannot decode FROM %T", from.expr)
+			}
+			b = b.FromSelect(sub, from.alias)
+		default:
+			return b, fmt.Errorf("sq: cannot decode FROM %T", e)
+		}
+	}
+
+	for _, hint := range v.FromHints {
+		if !d.AllowRaw && !tableHintPattern.MatchString(hint) {
+			return b, rawSQLError(hint)
+		}
+		b = b.FromHint(hint)
+	}
+
+	for _, raw := range v.Joins {
+		e, err := d.decodeSQLizer(raw, fragmentRaw)
+		if err != nil {
+			return b, err
+		}
+		// joins are added as they were built so that the scopes of the
+		// decoder's StatementBuilder still apply to them
+		switch e := e.(type) {
+		case *joinExpr:
+			b = builder.Append(b, "Joins", e).(SelectBuilder)
+		case expr:
+			b = b.JoinClause(e.sql, e.args...)
+		default:
+			b = b.JoinClause(e)
+		}
+	}
+
+	for _, raw := range v.Where {
+		e, err := d.decodeSQLizer(raw, fragmentPredicate)
+		if err != nil {
+			return b, err
+		}
+		b = b.Where(e)
+	}
+
+	for _, raw := range v.GroupBy {
+		e, err := d.decodeExpr(raw, fragmentGroupBy)
+		if err != nil {
+			return b, err
+		}
+		b = b.GroupByClause(e)
+	}
+	if v.WithRollup {
+		b = b.WithRollup()
+	}
+
+	for _, raw := range v.Having {
+		e, err := d.decodeSQLizer(raw, fragmentPredicate)
+		if err != nil {
+			return b, err
+		}
+		b = b.Having(e)
+	}
+
+	for _, raw := range v.OrderBy {
+		e, err := d.decodeExpr(raw, fragmentOrderBy)
+		if err != nil {
+			return b, err
+		}
+		b = b.OrderByClause(e)
+	}
+
+	if v.Limit != nil {
+		n, e, err := d.decodeCount(v.Limit)
+		if err != nil {
+			return b, err
+		}
+		if e != nil {
+			b = b.LimitExpr(e)
+		} else {
+			b = b.Limit(n)
+		}
+	}
+	if v.Offset != nil {
+		n, e, err := d.decodeCount(v.Offset)
+		if err != nil {
+			return b, err
+		}
+		if e != nil {
+			b = b.OffsetExpr(e)
+		} else {
+			b = b.Offset(n)
+		}
+	}
+	if v.BindLimits {
+		b = b.BindLimits()
+	}
+	if v.Pagination != "" {
+		p, err := paginationByName(v.Pagination)
+		if err != nil {
+			return b, err
+		}
+		b = b.Pagination(p)
+	}
+	if v.LimitPercent {
+		b = b.LimitPercent()
+	}
+	if v.LimitWithTies {
+		b = b.LimitWithTies()
+	}
+
+	for _, raw := range v.Suffixes {
+		e, err := d.decodeSQLizer(raw, fragmentRaw)
+		if err != nil {
+			return b, err
+		}
+		b = b.SuffixExpr(e)
+	}
+
+	if v.Unscoped {
+		if !d.AllowRaw && !d.AllowUnscoped {
+			return b, errors.New("sq: unscoped queries are not allowed")
+		}
+		b = b.Unscoped()
+	}
+	return b, nil
+}
+
+// fragment is the kind of SQL fragment a JSON string is decoded as.
+type fragment int
+
+const (
+	// fragmentRaw is any SQL.
+	fragmentRaw fragment = iota
+	// fragmentPredicate is a predicate, which is only ever raw as a string.
+	fragmentPredicate
+	// fragmentColumn is a column name with an optional alias.
+	fragmentColumn
+	// fragmentTable is a table name with an optional alias.
+	fragmentTable
+	// fragmentOrderBy is a column name with an optional direction.
+	fragmentOrderBy
+	// fragmentGroupBy is a column name in GROUP BY or DISTINCT ON.
+	fragmentGroupBy
+)
+
+var (
+	identSegment = "(?:[A-Za-z_][A-Za-z0-9_$]*|\"[^\"]+\"|`[^`]+`)"
+	ident        = identSegment + `(?:\.` + identSegment + `)*`
+	alias        = `(?:\s+(?:(?i:AS)\s+)?` + identSegment + `)?`
+
+	identPattern     = regexp.MustCompile(`^` + ident + `$`)
+	keywordPattern   = regexp.MustCompile(`^[A-Za-z_]+$`)
+	tableHintPattern = regexp.MustCompile(`^(?i:(?:USE|FORCE|IGNORE)\s+INDEX|WITH)\s*\(` +
+		identSegment + `(?:\s*,\s*` + identSegment + `)*\)$`)
+	// hintPattern matches optimizer hints taking identifiers and numbers, e.g.
+	// "NO_ICP(users)" or "MAX_EXECUTION_TIME(1000)", but not ones setting
+	// variables such as SET_VAR.
+	hintPattern = regexp.MustCompile(`^[A-Za-z_]+(?:\(\s*(?:(?:` + identSegment + `|\d+)(?:(?:\s*,\s*|\s+)(?:` +
+		identSegment + `|\d+))*)?\s*\))?$`)
+	typePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_ ]*(?:\(\d+(?:,\s*\d+)?\))?$`)
+
+	fragmentPatterns = map[fragment]*regexp.Regexp{
+		fragmentColumn:  regexp.MustCompile(`^(?:\*|` + ident + `(?:\.\*)?` + alias + `)$`),
+		fragmentTable:   regexp.MustCompile(`^` + ident + alias + `$`),
+		fragmentGroupBy: identPattern,
+		fragmentOrderBy: regexp.MustCompile(`^` + ident + `(?:\s+(?i:ASC|DESC))?(?:\s+(?i:NULLS\s+(?:FIRST|LAST)))?$`),
+	}
+
+	// jsonFuncs are the functions of the function helpers.
+	jsonFuncs = map[string]bool{
+		"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true,
+		"COALESCE": true, "NULLIF": true, "GREATEST": true, "LEAST": true,
+		"LOWER": true, "UPPER": true,
+		"GROUPING": true, "ROLLUP": true, "CUBE": true, "GROUPING SETS": true, "": true,
+	}
+)
+
+func rawSQLError(sql string) error {
+	return fmt.Errorf("sq: %w: %q", ErrRawSQL, sql)
+}
+
+func (d JSONDecoder) checkFragment(sql string, kind fragment) error {
+	if d.AllowRaw {
+		return nil
+	}
+	if pattern, ok := fragmentPatterns[kind]; ok && pattern.MatchString(sql) {
+		return nil
+	}
+	return rawSQLError(sql)
+}
+
+// decodeSQLizer is like decodeExpr, but turns strings into expressions.
+func (d JSONDecoder) decodeSQLizer(data json.RawMessage, kind fragment) (SQLizer, error) {
+	v, err := d.decodeExpr(data, kind)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := v.(string); ok {
+		return Expr(s), nil
+	}
+	return v.(SQLizer), nil
+}
+
+// decodeExpr decodes the JSON produced by marshalExpr into either a string or
+// a SQLizer. Strings are checked to be of the given kind.
+func (d JSONDecoder) decodeExpr(data json.RawMessage, kind fragment) (interface{}, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		if err := d.checkFragment(s, kind); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	if len(obj) != 1 {
+		return nil, fmt.Errorf("sq: expected an object with a single key, got %s", data)
+	}
+
+	for tag, v := range obj {
+		switch tag {
+		case "eq", "neq", "lt", "lte", "gt", "gte", "like", "nlike", "ilike", "nilike":
+			m, err := d.decodeMap(v)
+			if err != nil {
+				return nil, err
+			}
+			return mapPredicate(tag, m), nil
+		case "and", "or":
+			list, err := d.decodeList(v)
+			if err != nil {
+				return nil, err
+			}
+			if tag == "and" {
+				return And(list), nil
+			}
+			return Or(list), nil
+		case "expr":
+			var e struct {
+				SQL  string            `json:"sql"`
+				Args []json.RawMessage `json:"args"`
+			}
+			if err := json.Unmarshal(v, &e); err != nil {
+				return nil, err
+			}
+			if !d.AllowRaw {
+				return nil, rawSQLError(e.SQL)
+			}
+			args, err := d.decodeArgs(e.Args)
+			if err != nil {
+				return nil, err
+			}
+			return Expr(e.SQL, args...), nil
+		case "alias":
+			var e struct {
+				Expr json.RawMessage `json:"expr"`
+				As   string          `json:"as"`
+			}
+			if err := json.Unmarshal(v, &e); err != nil {
+				return nil, err
+			}
+			if !d.AllowRaw && !identPattern.MatchString(e.As) {
+				return nil, rawSQLError(e.As)
+			}
+			inner, err := d.decodeSQLizer(e.Expr, fragmentColumn)
+			if err != nil {
+				return nil, err
+			}
+			return Alias(inner, e.As), nil
+		case "func":
+			var e struct {
+				Name     string            `json:"name"`
+				Distinct bool              `json:"distinct"`
+				Args     []json.RawMessage `json:"args"`
+			}
+			if err := json.Unmarshal(v, &e); err != nil {
+				return nil, err
+			}
+			if !d.AllowRaw && !jsonFuncs[e.Name] {
+				return nil, rawSQLError(e.Name)
+			}
+			f := funcExpr{name: e.Name, distinct: e.Distinct, args: make([]interface{}, len(e.Args))}
+			for i, raw := range e.Args {
+				arg, err := d.decodeExpr(raw, fragmentColumn)
+				if err != nil {
+					return nil, err
+				}
+				f.args[i] = arg
+			}
+			return f, nil
+		case "cast":
+			var e struct {
+				Expr json.RawMessage `json:"expr"`
+				Type string          `json:"type"`
+			}
+			if err := json.Unmarshal(v, &e); err != nil {
+				return nil, err
+			}
+			if !d.AllowRaw && !typePattern.MatchString(e.Type) {
+				return nil, rawSQLError(e.Type)
+			}
+			inner, err := d.decodeExpr(e.Expr, fragmentColumn)
+			if err != nil {
+				return nil, err
+			}
+			return Cast(inner, e.Type), nil
+		case "order":
+			return d.decodeOrder(v)
+		case "join":
+			return d.decodeJoin(v)
+		case "select":
+			var sv selectJSON
+			if err := json.Unmarshal(v, &sv); err != nil {
+				return nil, err
+			}
+			return d.decodeSelect(&sv)
+		default:
+			return nil, fmt.Errorf("sq: unknown JSON expression %q", tag)
+		}
+	}
+	panic("unreachable")
+}
+
+// decodeCount decodes the value of a LIMIT or OFFSET clause encoded by
+// marshalCount into either a number or an expression.
+func (d JSONDecoder) decodeCount(data json.RawMessage) (uint64, SQLizer, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] >= '0' && data[0] <= '9' {
+		n, err := strconv.ParseUint(string(data), 10, 64)
+		return n, nil, err
+	}
+	e, err := d.decodeSQLizer(data, fragmentColumn)
+	return 0, e, err
+}
+
+func (d JSONDecoder) decodeOrder(data json.RawMessage) (SQLizer, error) {
+	var e struct {
+		Expr  json.RawMessage `json:"expr"`
+		Order string          `json:"order"`
+		Nulls string          `json:"nulls"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	inner, err := d.decodeExpr(e.Expr, fragmentColumn)
+	if err != nil {
+		return nil, err
+	}
+	o := orderExpr{expr: inner}
+	switch strings.ToLower(e.Order) {
+	case "", "asc":
+	case "desc":
+		o.order = Desc
+	default:
+		return nil, fmt.Errorf("sq: unknown order %q", e.Order)
+	}
+	switch strings.ToLower(e.Nulls) {
+	case "":
+	case "first":
+		o.nulls = NullsFirst
+	case "last":
+		o.nulls = NullsLast
+	default:
+		return nil, fmt.Errorf("sq: unknown nulls order %q", e.Nulls)
+	}
+	return o, nil
+}
+
+// joinKinds are the join keywords of joinExpr.
+var joinKinds = map[string]bool{
+	"JOIN": true, "LEFT JOIN": true, "RIGHT JOIN": true, "INNER JOIN": true, "CROSS JOIN": true,
+	"FULL OUTER JOIN": true, "NATURAL JOIN": true, "NATURAL LEFT JOIN": true,
+	"CROSS APPLY": true, "OUTER APPLY": true,
+}
+
+func (d JSONDecoder) decodeJoin(data json.RawMessage) (SQLizer, error) {
+	var e struct {
+		Kind    string          `json:"kind"`
+		Lateral bool            `json:"lateral"`
+		Table   json.RawMessage `json:"table"`
+		Alias   string          `json:"alias"`
+		Hints   []string        `json:"hints"`
+		On      json.RawMessage `json:"on"`
+		Using   []string        `json:"using"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	if !joinKinds[e.Kind] {
+		return nil, fmt.Errorf("sq: unknown join %q", e.Kind)
+	}
+	if e.Alias != "" && !d.AllowRaw && !identPattern.MatchString(e.Alias) {
+		return nil, rawSQLError(e.Alias)
+	}
+	table, err := d.decodeExpr(e.Table, fragmentTable)
+	if err != nil {
+		return nil, err
+	}
+	for _, hint := range e.Hints {
+		if !d.AllowRaw && !tableHintPattern.MatchString(hint) {
+			return nil, rawSQLError(hint)
+		}
+	}
+	for _, col := range e.Using {
+		if !d.AllowRaw && !identPattern.MatchString(col) {
+			return nil, rawSQLError(col)
+		}
+	}
+	j := &joinExpr{kind: e.Kind, lateral: e.Lateral, table: table, alias: e.Alias, hints: e.Hints, using: e.Using}
+	if len(e.On) > 0 && string(e.On) != "null" {
+		if j.on, err = d.decodeSQLizer(e.On, fragmentPredicate); err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// mapTags are the tags of the map predicates.
+var mapTags = map[string]bool{
+	"eq": true, "neq": true, "lt": true, "lte": true, "gt": true, "gte": true,
+	"like": true, "nlike": true, "ilike": true, "nilike": true,
+}
+
+// isTaggedMap reports whether data encodes a map predicate as produced by
+// marshalExpr, rather than a plain map: a single tag whose value is an object,
+// which can't be the value of a column.
+func isTaggedMap(data []byte) bool {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil || len(obj) != 1 {
+		return false
+	}
+	for tag, v := range obj {
+		v = bytes.TrimSpace(v)
+		return mapTags[tag] && len(v) > 0 && v[0] == '{'
+	}
+	return false
+}
+
+// mapPredicateTag returns the tag of the map predicate dst points to, or "".
+func mapPredicateTag(dst interface{}) string {
+	switch dst.(type) {
+	case *Eq:
+		return "eq"
+	case *NotEq:
+		return "neq"
+	case *Lt:
+		return "lt"
+	case *LtOrEq:
+		return "lte"
+	case *Gt:
+		return "gt"
+	case *GtOrEq:
+		return "gte"
+	case *Like:
+		return "like"
+	case *NotLike:
+		return "nlike"
+	case *ILike:
+		return "ilike"
+	case *NotILike:
+		return "nilike"
+	}
+	return ""
+}
+
+func mapPredicate(tag string, m map[string]interface{}) SQLizer {
+	switch tag {
+	case "neq":
+		return NotEq(m)
+	case "lt":
+		return Lt(m)
+	case "lte":
+		return LtOrEq(m)
+	case "gt":
+		return Gt(m)
+	case "gte":
+		return GtOrEq(m)
+	case "like":
+		return Like(m)
+	case "nlike":
+		return NotLike(m)
+	case "ilike":
+		return ILike(m)
+	case "nilike":
+		return NotILike(m)
+	default:
+		return Eq(m)
+	}
+}
+
+func (d JSONDecoder) decodeMap(data json.RawMessage) (map[string]interface{}, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{}, len(raw))
+	for key, v := range raw {
+		if !d.AllowRaw && !identPattern.MatchString(key) {
+			return nil, rawSQLError(key)
+		}
+		val, err := decodeValue(v)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = val
+	}
+	return m, nil
+}
+
+func (d JSONDecoder) decodeList(data json.RawMessage) ([]SQLizer, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	list := make([]SQLizer, len(raw))
+	for i, v := range raw {
+		s, err := d.decodeSQLizer(v, fragmentPredicate)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = s
+	}
+	return list, nil
+}
+
+func (d JSONDecoder) decodeArgs(raw []json.RawMessage) ([]interface{}, error) {
+	args := make([]interface{}, len(raw))
+	for i, v := range raw {
+		if trimmed := bytes.TrimSpace(v); len(trimmed) > 0 && trimmed[0] == '{' {
+			s, err := d.decodeSQLizer(v, fragmentRaw)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = s
+			continue
+		}
+		arg, err := decodeValue(v)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = arg
+	}
+	return args, nil
+}
+
+// decodeValue decodes a bound value: null, a boolean, a number (as an int64
+// if it is an integer), a string or a list of those.
+func decodeValue(data json.RawMessage) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return convertValue(v)
+}
+
+func convertValue(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n, nil
+		}
+		return val.Float64()
+	case []interface{}:
+		for i, item := range val {
+			item, err := convertValue(item)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = item
+		}
+		return val, nil
+	case map[string]interface{}:
+		return nil, errors.New("sq: cannot decode JSON object as a value")
+	default:
+		return val, nil
+	}
+}
+
+func placeholderFormatByName(name string) (PlaceholderFormat, error) {
+	switch strings.ToLower(name) {
+	case "question":
+		return Question, nil
+	case "dollar":
+		return Dollar, nil
+	case "colon":
+		return Colon, nil
+	case "atp":
+		return AtP, nil
+	default:
+		return nil, fmt.Errorf("sq: unknown placeholder format %q", name)
+	}
+}
+
+func dialectByName(name string) (Dialect, error) {
+	switch strings.ToLower(name) {
+	case "postgres":
+		return Postgres, nil
+	case "mysql":
+		return MySQL, nil
+	case "sqlite":
+		return SQLite, nil
+	case "sqlserver":
+		return SQLServer, nil
+	default:
+		return 0, fmt.Errorf("sq: unknown dialect %q", name)
+	}
+}
+
+func paginationByName(name string) (Pagination, error) {
+	switch strings.ToLower(name) {
+	case "limitoffset":
+		return LimitOffset, nil
+	case "offsetfetch":
+		return OffsetFetch, nil
+	case "topoffsetfetch":
+		return TopOffsetFetch, nil
+	default:
+		return 0, fmt.Errorf("sq: unknown pagination %q", name)
+	}
+}
+
+// unmarshalPredicate decodes data into dst, which must point to a predicate of
+// the same type as the one encoded. Map predicates are also decoded from plain
+// maps, e.g. {"id": 1}.
+func unmarshalPredicate(data []byte, dst interface{}) error {
+	var s SQLizer
+	var err error
+	if tag := mapPredicateTag(dst); tag != "" && !isTaggedMap(data) {
+		var m map[string]interface{}
+		if m, err = (JSONDecoder{}).decodeMap(data); err == nil {
+			s = mapPredicate(tag, m)
+		}
+	} else {
+		s, err = JSONDecoder{}.DecodePredicate(data)
+	}
+	if err != nil {
+		return err
+	}
+	ok := true
+	switch p := dst.(type) {
+	case *Eq:
+		*p, ok = s.(Eq)
+	case *NotEq:
+		*p, ok = s.(NotEq)
+	case *Lt:
+		*p, ok = s.(Lt)
+	case *LtOrEq:
+		*p, ok = s.(LtOrEq)
+	case *Gt:
+		*p, ok = s.(Gt)
+	case *GtOrEq:
+		*p, ok = s.(GtOrEq)
+	case *Like:
+		*p, ok = s.(Like)
+	case *NotLike:
+		*p, ok = s.(NotLike)
+	case *ILike:
+		*p, ok = s.(ILike)
+	case *NotILike:
+		*p, ok = s.(NotILike)
+	case *And:
+		*p, ok = s.(And)
+	case *Or:
+		*p, ok = s.(Or)
+	}
+	if !ok {
+		return fmt.Errorf("sq: cannot decode %T into %T", s, dst)
+	}
+	return nil
+}
+
+// MarshalJSON encodes eq as {"eq": {...}}.
+func (eq Eq) MarshalJSON() ([]byte, error) { return marshalRaw(eq) }
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON or a plain map.
+func (eq *Eq) UnmarshalJSON(data []byte) error { return unmarshalPredicate(data, eq) }
+
+// MarshalJSON encodes neq as {"neq": {...}}.
+func (neq NotEq) MarshalJSON() ([]byte, error) { return marshalRaw(neq) }
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON or a plain map.
+func (neq *NotEq) UnmarshalJSON(data []byte) error { return unmarshalPredicate(data, neq) }
+
+// MarshalJSON encodes lt as {"lt": {...}}.
+func (lt Lt) MarshalJSON() ([]byte, error) { return marshalRaw(lt) }
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON or a plain map.
+func (lt *Lt) UnmarshalJSON(data []byte) error { return unmarshalPredicate(data, lt) }
+
+// MarshalJSON encodes ltOrEq as {"lte": {...}}.
+func (ltOrEq LtOrEq) MarshalJSON() ([]byte, error) { return marshalRaw(ltOrEq) }
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON or a plain map.
+func (ltOrEq *LtOrEq) UnmarshalJSON(data []byte) error { return unmarshalPredicate(data, ltOrEq) }
+
+// MarshalJSON encodes gt as {"gt": {...}}.
+func (gt Gt) MarshalJSON() ([]byte, error) { return marshalRaw(gt) }
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON or a plain map.
+func (gt *Gt) UnmarshalJSON(data []byte) error { return unmarshalPredicate(data, gt) }
+
+// MarshalJSON encodes gtOrEq as {"gte": {...}}.
+func (gtOrEq GtOrEq) MarshalJSON() ([]byte, error) { return marshalRaw(gtOrEq) }
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON or a plain map.
+func (gtOrEq *GtOrEq) UnmarshalJSON(data []byte) error { return unmarshalPredicate(data, gtOrEq) }
+
+// MarshalJSON encodes lk as {"like": {...}}.
+func (lk Like) MarshalJSON() ([]byte, error) { return marshalRaw(lk) }
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON or a plain map.
+func (lk *Like) UnmarshalJSON(data []byte) error { return unmarshalPredicate(data, lk) }
+
+// MarshalJSON encodes nlk as {"nlike": {...}}.
+func (nlk NotLike) MarshalJSON() ([]byte, error) { return marshalRaw(nlk) }
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON or a plain map.
+func (nlk *NotLike) UnmarshalJSON(data []byte) error { return unmarshalPredicate(data, nlk) }
+
+// MarshalJSON encodes ilk as {"ilike": {...}}.
+func (ilk ILike) MarshalJSON() ([]byte, error) { return marshalRaw(ilk) }
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON or a plain map.
+func (ilk *ILike) UnmarshalJSON(data []byte) error { return unmarshalPredicate(data, ilk) }
+
+// MarshalJSON encodes nilk as {"nilike": {...}}.
+func (nilk NotILike) MarshalJSON() ([]byte, error) { return marshalRaw(nilk) }
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON or a plain map.
+func (nilk *NotILike) UnmarshalJSON(data []byte) error { return unmarshalPredicate(data, nilk) }
+
+// MarshalJSON encodes a as {"and": [...]}.
+func (a And) MarshalJSON() ([]byte, error) { return marshalRaw(a) }
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON.
+func (a *And) UnmarshalJSON(data []byte) error { return unmarshalPredicate(data, a) }
+
+// MarshalJSON encodes o as {"or": [...]}.
+func (o Or) MarshalJSON() ([]byte, error) { return marshalRaw(o) }
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON.
+func (o *Or) UnmarshalJSON(data []byte) error { return unmarshalPredicate(data, o) }
@@ -0,0 +1,216 @@
+package sq
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectBuilderJSONRoundTrip(t *testing.T) {
+	sub := Select("id").From("orgs").Where(Eq{"active": true})
+	b := Select("u.id", "u.name AS n").
+		Column(Alias(Count("*"), "total")).
+		Column(Cast("u.score", "NUMERIC(10, 2)")).
+		FromSelect(sub, "o").
+		Where(And{Eq{"o.kind": []interface{}{"a", "b"}}, Or{Gt{"age": 18}, Like{"name": "jo%"}}}).
+		Where(NotEq{"deleted_at": nil}).
+		GroupBy("u.id").
+		Having(GtOrEq{"total": 2}).
+		OrderBy("u.name DESC", "u.id").
+		Limit(10).
+		Offset(20).
+		PlaceholderFormat(Dollar)
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+
+	var decoded SelectBuilder
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+
+	expectedSQL, expectedArgs, err := b.ToSQL()
+	assert.NoError(t, err)
+	sql, args, err := decoded.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{true, "a", "b", int64(18), "jo%", int64(2)}, args)
+	assert.Len(t, expectedArgs, len(args))
+
+	again, err := json.Marshal(decoded)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(data), string(again))
+}
+
+func TestSelectBuilderMarshalJSON(t *testing.T) {
+	b := Select("id").From("users").Where(Eq{"id": 1}).Limit(5)
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+	expected := `{
+		"version": 1,
+		"placeholderFormat": "question",
+		"columns": ["id"],
+		"from": "users",
+		"where": [{"eq": {"id": 1}}],
+		"limit": 5
+	}`
+	assert.JSONEq(t, expected, string(data))
+}
+
+func TestSelectBuilderMarshalJSONUnsupported(t *testing.T) {
+	b := Select("id").From("users").Column(Case().When("a", "1"))
+	_, err := json.Marshal(b)
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderUnmarshalJSONRejectsRawSQL(t *testing.T) {
+	docs := []string{
+		`{"version":1,"columns":["id; DROP TABLE users"]}`,
+		`{"version":1,"columns":["id"],"from":"users WHERE 1=1"}`,
+		`{"version":1,"columns":["id"],"where":["id = 1"]}`,
+		`{"version":1,"columns":["id"],"where":[{"expr":{"sql":"id = ?","args":[1]}}]}`,
+		`{"version":1,"columns":["id"],"where":[{"eq":{"id = 1 OR id":1}}]}`,
+		`{"version":1,"columns":["id"],"orderBy":["(SELECT 1)"]}`,
+		`{"version":1,"columns":["id"],"groupBy":["1; --"]}`,
+		`{"version":1,"columns":["id"],"joins":["JOIN orgs"]}`,
+		`{"version":1,"columns":["id"],"suffixes":["FOR UPDATE"]}`,
+		`{"version":1,"columns":[{"func":{"name":"PG_SLEEP","args":[]}}]}`,
+	}
+	for _, doc := range docs {
+		var b SelectBuilder
+		err := json.Unmarshal([]byte(doc), &b)
+		assert.True(t, errors.Is(err, ErrRawSQL), "%s: %v", doc, err)
+	}
+}
+
+func TestJSONDecoderAllowRaw(t *testing.T) {
+	doc := `{
+		"version": 1,
+		"columns": ["id"],
+		"from": "users",
+		"joins": ["JOIN orgs o ON o.id = users.org_id"],
+		"where": ["users.deleted_at IS NULL", {"expr": {"sql": "o.id IN (?)", "args": [{"select": {"columns": ["id"], "from": "orgs", "where": [{"eq": {"active": true}}]}}]}}],
+		"suffixes": ["FOR UPDATE"]
+	}`
+	b, err := JSONDecoder{AllowRaw: true}.DecodeSelect([]byte(doc))
+	assert.NoError(t, err)
+
+	sql, args, err := b.ToSQL()
+	assert.NoError(t, err)
+	expectedSQL := "SELECT id FROM users JOIN orgs o ON o.id = users.org_id " +
+		"WHERE users.deleted_at IS NULL AND o.id IN (SELECT id FROM orgs WHERE active = ?) FOR UPDATE"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{true}, args)
+}
+
+func TestJSONDecoderStatementBuilder(t *testing.T) {
+	sb := StatementBuilder.Scope("users", Eq{"deleted_at": nil})
+	b, err := JSONDecoder{StatementBuilder: sb}.DecodeSelect([]byte(`{"version":1,"columns":["id"],"from":"users"}`))
+	assert.NoError(t, err)
+
+	sql, _, err := b.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users WHERE users.deleted_at IS NULL", sql)
+}
+
+func TestJSONRoundTripScopedJoins(t *testing.T) {
+	sb := StatementBuilder.Scope("orgs", Eq{"tenant_id": 7})
+	b := sb.Select("u.id").
+		From("users u").
+		LeftJoinOn("orgs", "o", Expr("o.id = u.org_id")).
+		Join("orgs p ON p.id = u.parent_org_id")
+
+	expectedSQL, expectedArgs, err := b.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"SELECT u.id FROM users u LEFT JOIN orgs AS o ON (o.id = u.org_id) AND o.tenant_id = ? "+
+			"JOIN orgs p ON p.id = u.parent_org_id WHERE p.tenant_id = ?",
+		expectedSQL)
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+	decoded, err := JSONDecoder{AllowRaw: true, StatementBuilder: sb}.DecodeSelect(data)
+	assert.NoError(t, err)
+
+	sql, args, err := decoded.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestJSONDecoderUnscoped(t *testing.T) {
+	sb := StatementBuilder.Scope("users", Eq{"deleted_at": nil})
+	doc := []byte(`{"version":1,"columns":["id"],"from":"users","unscoped":true}`)
+
+	_, err := JSONDecoder{StatementBuilder: sb}.DecodeSelect(doc)
+	assert.Error(t, err)
+
+	var b SelectBuilder
+	assert.Error(t, json.Unmarshal(doc, &b))
+
+	for _, d := range []JSONDecoder{{StatementBuilder: sb, AllowUnscoped: true}, {StatementBuilder: sb, AllowRaw: true}} {
+		b, err := d.DecodeSelect(doc)
+		assert.NoError(t, err)
+
+		sql, _, err := b.ToSQL()
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT id FROM users", sql)
+	}
+}
+
+func TestSelectBuilderUnmarshalJSONVersion(t *testing.T) {
+	var b SelectBuilder
+	assert.Error(t, json.Unmarshal([]byte(`{"columns":["id"]}`), &b))
+	assert.Error(t, json.Unmarshal([]byte(`{"version":2,"columns":["id"]}`), &b))
+}
+
+func TestPredicateJSONRoundTrip(t *testing.T) {
+	preds := []SQLizer{
+		Eq{"a": 1, "b": nil, "c": []interface{}{"x", "y"}},
+		NotEq{"a": 1.5},
+		Lt{"a": 1},
+		LtOrEq{"a": 1},
+		Gt{"a": 1},
+		GtOrEq{"a": 1},
+		Like{"a": "x%"},
+		NotLike{"a": "x%"},
+		ILike{"a": "x%"},
+		NotILike{"a": "x%"},
+		And{Eq{"a": 1}, Or{Eq{"b": 2}, Eq{"c": 3}}},
+	}
+	for _, pred := range preds {
+		data, err := json.Marshal(pred)
+		assert.NoError(t, err)
+
+		decoded, err := JSONDecoder{}.DecodePredicate(data)
+		assert.NoError(t, err)
+
+		expectedSQL, _, _ := pred.ToSQL()
+		sql, _, err := decoded.ToSQL()
+		assert.NoError(t, err)
+		assert.Equal(t, expectedSQL, sql)
+	}
+}
+
+func TestPredicateUnmarshalJSON(t *testing.T) {
+	var eq Eq
+	assert.NoError(t, json.Unmarshal([]byte(`{"eq":{"id":1,"name":"x"}}`), &eq))
+	assert.Equal(t, Eq{"id": int64(1), "name": "x"}, eq)
+
+	var and And
+	assert.NoError(t, json.Unmarshal([]byte(`{"and":[{"gt":{"a":1}},{"lt":{"a":2.5}}]}`), &and))
+	assert.Equal(t, And{Gt{"a": int64(1)}, Lt{"a": 2.5}}, and)
+
+	var plain Eq
+	assert.NoError(t, json.Unmarshal([]byte(`{"id":1,"eq":"x","ids":[1,2]}`), &plain))
+	assert.Equal(t, Eq{"id": int64(1), "eq": "x", "ids": []interface{}{int64(1), int64(2)}}, plain)
+
+	var gt Gt
+	assert.NoError(t, json.Unmarshal([]byte(`{"age":18}`), &gt))
+	assert.Equal(t, Gt{"age": int64(18)}, gt)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"id = 1 OR 1":1}`), &plain))
+	assert.Error(t, json.Unmarshal([]byte(`{"neq":{"id":1}}`), &eq))
+	assert.Error(t, json.Unmarshal([]byte(`{"eq":{"id":{"nested":1}}}`), &eq))
+}
@@ -0,0 +1,453 @@
+package sq
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+type mergeData struct {
+	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	Prefixes          []SQLizer
+	Into              string
+	Using             interface{}
+	UsingAlias        string
+	OnParts           []SQLizer
+	Whens             []*mergeWhen
+	Suffixes          []SQLizer
+	Scopes            []scope
+	Unscoped          bool
+	Comments          []map[string]string
+	Commenter         func() map[string]string
+}
+
+// mergeWhen is a WHEN [NOT] MATCHED clause of a MERGE statement.
+type mergeWhen struct {
+	matched bool
+	cond    SQLizer
+	// action is the keyword of the action, e.g. "UPDATE" or "DO NOTHING".
+	action  string
+	sets    []setClause
+	columns []string
+	values  []interface{}
+	caller  callSite
+}
+
+func (c *mergeWhen) site() *callSite {
+	return &c.caller
+}
+
+func (c *mergeWhen) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(c)
+}
+
+func (c *mergeWhen) writeSQL(w *sqlWriter) {
+	if c.matched {
+		w.WriteString("WHEN MATCHED")
+	} else {
+		w.WriteString("WHEN NOT MATCHED")
+	}
+	if c.cond != nil {
+		w.WriteString(" AND ")
+		w.WriteSQL(c.cond)
+	}
+	w.WriteString(" THEN ")
+
+	switch c.action {
+	case "UPDATE":
+		if len(c.sets) == 0 {
+			w.Fail(errors.New("merge updates must have at least one column"))
+			return
+		}
+		w.WriteString("UPDATE ")
+		writeSetClauses(w, c.sets)
+	case "INSERT":
+		if len(c.values) == 0 {
+			w.Fail(errors.New("merge inserts must have at least one value"))
+			return
+		}
+		if len(c.columns) > 0 && len(c.columns) != len(c.values) {
+			w.Fail(fmt.Errorf("merge insert has %d columns but %d values", len(c.columns), len(c.values)))
+			return
+		}
+		w.WriteString("INSERT ")
+		if len(c.columns) > 0 {
+			w.WriteString("(")
+			w.WriteString(strings.Join(c.columns, ","))
+			w.WriteString(") ")
+		}
+		w.WriteString("VALUES (")
+		for i, val := range c.values {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if vs, ok := val.(SQLizer); ok {
+				w.WriteSQL(vs)
+			} else {
+				w.WriteArg(val)
+			}
+		}
+		w.WriteByte(')')
+	case "DO NOTHING":
+		if w.dialect != Postgres {
+			w.Fail(fmt.Errorf("merge DO NOTHING is not supported for %s", w.dialect))
+			return
+		}
+		w.WriteString(c.action)
+	default:
+		w.WriteString(c.action)
+	}
+}
+
+func (d *mergeData) ToSQL() (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = rawToSQL(d)
+	if err != nil {
+		return
+	}
+
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr += sqlComment(d.Commenter, d.Comments)
+	return
+}
+
+func (d *mergeData) toSQLPretty(indent string) (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = prettyToSQL(d, indent)
+	if err != nil {
+		return
+	}
+
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr += sqlComment(d.Commenter, d.Comments)
+	return
+}
+
+func (d *mergeData) writeSQL(w *sqlWriter) {
+	defer w.setDialect(d.Dialect)()
+
+	if len(d.Into) == 0 {
+		w.Fail(errors.New("merge statements must specify a target table"))
+		return
+	}
+	if d.Using == nil {
+		w.Fail(errors.New("merge statements must specify a Using source"))
+		return
+	}
+	if len(d.OnParts) == 0 {
+		w.Fail(errors.New("merge statements must have an On condition"))
+		return
+	}
+	if len(d.Whens) == 0 {
+		w.Fail(errors.New("merge statements must have at least one WHEN clause"))
+		return
+	}
+	if d.Dialect != Postgres && d.Dialect != SQLServer {
+		w.Fail(fmt.Errorf("merge statements are not supported for %s", d.Dialect))
+		return
+	}
+	// the scopes of the target can't go in ON, where they would turn the
+	// target rows of other scopes into unmatched ones to insert
+	if !d.Unscoped && len(scopeTables(d.Into, d.Scopes)) > 0 {
+		w.Fail(fmt.Errorf("merge statements can't apply the scopes of %s, "+
+			"use Unscoped and restrict the target rows in the conditions", d.Into))
+		return
+	}
+
+	if len(d.Prefixes) > 0 {
+		w.WriteClause("PREFIX", d.Prefixes, " ")
+		w.Break()
+	}
+
+	w.WriteString("MERGE INTO ")
+	w.WriteString(d.Into)
+
+	w.Break()
+	w.WriteString("USING ")
+	switch using := d.Using.(type) {
+	case string:
+		w.WriteString(using)
+	case SQLizer:
+		if isStatement(using) {
+			if d.UsingAlias == "" {
+				w.Fail(errors.New("merge statements using a subquery require an alias"))
+				return
+			}
+			w.WriteSubquery(using)
+		} else {
+			w.WriteClause("USING", []SQLizer{using}, "")
+		}
+	default:
+		w.Fail(fmt.Errorf("merge Using expected a table name or SQLizer, not %T", d.Using))
+		return
+	}
+	if d.UsingAlias != "" {
+		w.WriteString(" AS ")
+		w.WriteString(d.UsingAlias)
+	}
+
+	w.Break()
+	w.WriteString("ON ")
+	w.WriteConditions("ON", d.OnParts)
+
+	whens := make([]SQLizer, len(d.Whens))
+	for i, when := range d.Whens {
+		whens[i] = when
+	}
+	w.Break()
+	w.WriteClause("WHEN", whens, w.BreakSep())
+
+	if len(d.Suffixes) > 0 {
+		w.Break()
+		w.WriteClause("SUFFIX", d.Suffixes, " ")
+	}
+
+	// SQL Server requires MERGE statements to be terminated
+	if d.Dialect == SQLServer {
+		w.WriteByte(';')
+	}
+}
+
+// Builder
+
+// MergeBuilder builds SQL MERGE statements, which insert, update or delete the
+// rows of a target table depending on whether they match the rows of a source
+// table or query.
+//
+// The Postgres (15 and later) and SQLServer dialects are supported. Oracle's
+// MERGE, which takes no AS before the source alias and puts the conditions of
+// the WHEN clauses in WHERE clauses of their actions, is not.
+//
+// Ex:
+//
+//	Merge("accounts a").
+//		Using(Select("id", "balance").From("staged"), "s").
+//		On("a.id = s.id").
+//		WhenMatched(Expr("s.balance = 0")).Delete().
+//		WhenMatched(nil).Update(map[string]interface{}{"balance": Expr("s.balance")}).
+//		WhenNotMatched(nil).Insert([]string{"id", "balance"}, Expr("s.id"), Expr("s.balance"))
+//	// MERGE INTO accounts a USING (SELECT id, balance FROM staged) AS s ON a.id = s.id
+//	// WHEN MATCHED AND s.balance = 0 THEN DELETE
+//	// WHEN MATCHED THEN UPDATE SET balance = s.balance
+//	// WHEN NOT MATCHED THEN INSERT (id,balance) VALUES (s.id,s.balance)
+type MergeBuilder builder.Builder
+
+func init() {
+	builder.Register(MergeBuilder{}, mergeData{})
+}
+
+// Format methods
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
+// query.
+func (b MergeBuilder) PlaceholderFormat(f PlaceholderFormat) MergeBuilder {
+	return builder.Set(b, "PlaceholderFormat", f).(MergeBuilder)
+}
+
+// Dialect sets the dialect of the query. SQL Server statements are terminated
+// by a semicolon, which it requires.
+//
+// See SelectBuilder.Dialect.
+func (b MergeBuilder) Dialect(d Dialect) MergeBuilder {
+	return builder.Set(b, "Dialect", d).(MergeBuilder)
+}
+
+// SQL methods
+
+// ToSQL builds the query into a SQL string and bound args.
+func (b MergeBuilder) ToSQL() (string, []interface{}, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.ToSQL()
+}
+
+// ToSQLPretty is like ToSQL, but puts the USING source, each WHEN clause and
+// each SET assignment on its own line.
+//
+// See SelectBuilder.ToSQLPretty for more information.
+func (b MergeBuilder) ToSQLPretty(indent string) (string, []interface{}, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.toSQLPretty(indent)
+}
+
+func (b MergeBuilder) writeSQL(w *sqlWriter) {
+	data := builder.GetStruct(b).(mergeData)
+	data.writeSQL(w)
+}
+
+// MustSQL builds the query into a SQL string and bound args.
+// It panics if there are any errors.
+func (b MergeBuilder) MustSQL() (string, []interface{}) {
+	sql, args, err := b.ToSQL()
+	if err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
+// Prefix adds an expression to the beginning of the query.
+func (b MergeBuilder) Prefix(sql string, args ...interface{}) MergeBuilder {
+	return builder.Append(b, "Prefixes", newPart(Expr(sql, args...))).(MergeBuilder)
+}
+
+// PrefixExpr adds an expression to the very beginning of the query.
+func (b MergeBuilder) PrefixExpr(expr SQLizer) MergeBuilder {
+	return builder.Append(b, "Prefixes", newPart(expr)).(MergeBuilder)
+}
+
+// Unscoped allows the query to merge into a table with default scopes, which
+// MERGE statements can't apply, making it the responsibility of the On and
+// WHEN conditions to leave the rows out of the scopes alone.
+func (b MergeBuilder) Unscoped() MergeBuilder {
+	return builder.Set(b, "Unscoped", true).(MergeBuilder)
+}
+
+// Into sets the target table of the query, optionally with an alias.
+func (b MergeBuilder) Into(target string) MergeBuilder {
+	return builder.Set(b, "Into", target).(MergeBuilder)
+}
+
+// Using sets the source of the query, which is either a table name or a
+// SQLizer such as a SelectBuilder, with an alias which is required for
+// subqueries.
+func (b MergeBuilder) Using(source interface{}, alias string) MergeBuilder {
+	if s, ok := source.(SQLizer); ok && !isStatement(s) {
+		source = newPart(s)
+	}
+	b = builder.Set(b, "Using", source).(MergeBuilder)
+	return builder.Set(b, "UsingAlias", alias).(MergeBuilder)
+}
+
+// On adds an expression to the ON condition matching the rows of the target
+// and the source. Several expressions are ANDed.
+//
+// See SelectBuilder.Where for the accepted types of pred.
+func (b MergeBuilder) On(pred interface{}, args ...interface{}) MergeBuilder {
+	return builder.Append(b, "OnParts", newWherePart(pred, args...)).(MergeBuilder)
+}
+
+// WhenMatched starts a WHEN MATCHED clause, applying to the target rows which
+// match a source row and for which cond is true, if it isn't nil. cond accepts
+// the same types as SelectBuilder.Where.
+//
+// The clauses are tried in the order they were added.
+func (b MergeBuilder) WhenMatched(cond interface{}, args ...interface{}) MergeMatched {
+	return MergeMatched{b: b, when: newMergeWhen(true, cond, args)}
+}
+
+// WhenNotMatched starts a WHEN NOT MATCHED clause, applying to the source rows
+// which don't match any target row and for which cond is true, if it isn't nil.
+//
+// See WhenMatched.
+func (b MergeBuilder) WhenNotMatched(cond interface{}, args ...interface{}) MergeNotMatched {
+	return MergeNotMatched{b: b, when: newMergeWhen(false, cond, args)}
+}
+
+func newMergeWhen(matched bool, cond interface{}, args []interface{}) mergeWhen {
+	c := mergeWhen{matched: matched}
+	if cond != nil {
+		p := &wherePart{pred: cond, args: args}
+		p.caller.capture(1)
+		c.cond = p
+	}
+	return c
+}
+
+func (b MergeBuilder) when(c mergeWhen, action string) MergeBuilder {
+	c.action = action
+	c.caller.capture(1)
+	return builder.Append(b, "Whens", &c).(MergeBuilder)
+}
+
+// Suffix adds an expression to the end of the query.
+func (b MergeBuilder) Suffix(sql string, args ...interface{}) MergeBuilder {
+	return builder.Append(b, "Suffixes", newPart(Expr(sql, args...))).(MergeBuilder)
+}
+
+// SuffixExpr adds an expression to the end of the query.
+func (b MergeBuilder) SuffixExpr(expr SQLizer) MergeBuilder {
+	return builder.Append(b, "Suffixes", newPart(expr)).(MergeBuilder)
+}
+
+// Comment adds tags to the trailing comment of the statement.
+//
+// See SelectBuilder.Comment for more information.
+func (b MergeBuilder) Comment(tags map[string]string) MergeBuilder {
+	return builder.Append(b, "Comments", tags).(MergeBuilder)
+}
+
+// Apply calls fn with the builder and returns its result, which allows reusing
+// functions that add clauses to a query.
+func (b MergeBuilder) Apply(fn func(MergeBuilder) MergeBuilder) MergeBuilder {
+	return fn(b)
+}
+
+// ApplyIf calls Apply if cond is true, and returns the builder unchanged
+// otherwise.
+func (b MergeBuilder) ApplyIf(cond bool, fn func(MergeBuilder) MergeBuilder) MergeBuilder {
+	if !cond {
+		return b
+	}
+	return b.Apply(fn)
+}
+
+// MergeMatched is a WHEN MATCHED clause of a MergeBuilder, which is added to
+// it by one of its actions.
+type MergeMatched struct {
+	b    MergeBuilder
+	when mergeWhen
+}
+
+// Update updates the matched target rows, setting the columns of set to their
+// values, in the order of the columns. Values can be Default, Increment and
+// Decrement as well as any SQLizer, e.g. Expr("s.price") for a column of the
+// source.
+func (m MergeMatched) Update(set map[string]interface{}) MergeBuilder {
+	cols := make([]string, 0, len(set))
+	for col := range set {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	c := m.when
+	c.sets = make([]setClause, len(cols))
+	for i, col := range cols {
+		c.sets[i] = setClause{column: col, value: set[col]}
+	}
+	return m.b.when(c, "UPDATE")
+}
+
+// Delete deletes the matched target rows.
+func (m MergeMatched) Delete() MergeBuilder {
+	return m.b.when(m.when, "DELETE")
+}
+
+// DoNothing leaves the matched target rows unchanged, which is only supported
+// by Postgres.
+func (m MergeMatched) DoNothing() MergeBuilder {
+	return m.b.when(m.when, "DO NOTHING")
+}
+
+// MergeNotMatched is a WHEN NOT MATCHED clause of a MergeBuilder, which is
+// added to it by one of its actions.
+type MergeNotMatched struct {
+	b    MergeBuilder
+	when mergeWhen
+}
+
+// Insert inserts a target row of values into columns, or into every column in
+// order if columns is empty. Values can be Default as well as any SQLizer,
+// e.g. Expr("s.id") for a column of the source.
+func (m MergeNotMatched) Insert(columns []string, values ...interface{}) MergeBuilder {
+	c := m.when
+	c.columns = columns
+	c.values = values
+	return m.b.when(c, "INSERT")
+}
+
+// DoNothing skips the unmatched source rows, which is only supported by
+// Postgres.
+func (m MergeNotMatched) DoNothing() MergeBuilder {
+	return m.b.when(m.when, "DO NOTHING")
+}
@@ -0,0 +1,149 @@
+package sq
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeBuilderToSQL(t *testing.T) {
+	staged := Select("id", "balance").From("staged").Where(Eq{"batch": 7})
+	set := map[string]interface{}{
+		"balance":    Expr("s.balance"),
+		"updated_at": Default,
+		"version":    Increment(1),
+	}
+	b := Merge("accounts a").
+		Prefix("WITH x AS (SELECT ?)", 0).
+		Using(staged, "s").
+		On("a.id = s.id").
+		WhenMatched(Eq{"s.balance": 0}).Delete().
+		WhenMatched(nil).Update(set).
+		WhenNotMatched("s.balance > ?", 0).Insert([]string{"id", "balance"}, Expr("s.id"), Expr("s.balance")).
+		WhenNotMatched(nil).DoNothing().
+		Suffix("RETURNING merge_action()").
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSQL()
+	assert.NoError(t, err)
+
+	expectedSQL := "WITH x AS (SELECT $1) " +
+		"MERGE INTO accounts a " +
+		"USING (SELECT id, balance FROM staged WHERE batch = $2) AS s " +
+		"ON a.id = s.id " +
+		"WHEN MATCHED AND s.balance = $3 THEN DELETE " +
+		"WHEN MATCHED THEN UPDATE SET balance = s.balance, updated_at = DEFAULT, version = version + $4 " +
+		"WHEN NOT MATCHED AND s.balance > $5 THEN INSERT (id,balance) VALUES (s.id,s.balance) " +
+		"WHEN NOT MATCHED THEN DO NOTHING " +
+		"RETURNING merge_action()"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{0, 7, 0, 1, 0}, args)
+}
+
+func TestMergeBuilderUsingTable(t *testing.T) {
+	sql, args, err := StatementBuilder.Dialect(SQLServer).
+		Merge("products").
+		Using("incoming", "i").
+		On(Expr("products.sku = i.sku")).
+		On("i.active = ?", true).
+		WhenMatched(nil).Update(map[string]interface{}{"price": Expr("i.price")}).
+		WhenNotMatched(nil).Insert(nil, Expr("i.sku"), Expr("i.price"), 0).
+		ToSQL()
+	assert.NoError(t, err)
+
+	expectedSQL := "MERGE INTO products USING incoming AS i ON products.sku = i.sku AND i.active = ? " +
+		"WHEN MATCHED THEN UPDATE SET price = i.price " +
+		"WHEN NOT MATCHED THEN INSERT VALUES (i.sku,i.price,?);"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{true, 0}, args)
+}
+
+func TestMergeBuilderScopes(t *testing.T) {
+	sb := StatementBuilder.Scope("accounts", Eq{"tenant_id": 7})
+	b := sb.Merge("accounts a").
+		Using("staged", "s").
+		On("a.id = s.id AND a.tenant_id = ?", 7).
+		WhenMatched(nil).Delete()
+
+	_, _, err := b.ToSQL()
+	assert.EqualError(t, err, "merge statements can't apply the scopes of accounts a, "+
+		"use Unscoped and restrict the target rows in the conditions")
+
+	sql, args, err := b.Unscoped().ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO accounts a USING staged AS s ON a.id = s.id AND a.tenant_id = ? WHEN MATCHED THEN DELETE", sql)
+	assert.Equal(t, []interface{}{7}, args)
+
+	// scopes of other tables don't apply
+	_, _, err = sb.Merge("ledger").Using("staged", "s").On("ledger.id = s.id").WhenMatched(nil).Delete().ToSQL()
+	assert.NoError(t, err)
+}
+
+func TestMergeBuilderImmutable(t *testing.T) {
+	b := Merge("t").Using("s", "").On("t.id = s.id")
+	b1 := b.WhenMatched(nil).Delete()
+	b2 := b.WhenNotMatched(nil).Insert([]string{"id"}, Expr("s.id"))
+
+	sql, _, err := b1.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN DELETE", sql)
+
+	sql, _, err = b2.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s ON t.id = s.id WHEN NOT MATCHED THEN INSERT (id) VALUES (s.id)", sql)
+}
+
+func TestMergeBuilderToSQLPretty(t *testing.T) {
+	sql, _, err := Merge("t").
+		Using(Select("id").From("s"), "s").
+		On("t.id = s.id").
+		WhenMatched(nil).Update(map[string]interface{}{"a": 1, "b": 2}).
+		WhenNotMatched(nil).Insert([]string{"id"}, Expr("s.id")).
+		ToSQLPretty("  ")
+	assert.NoError(t, err)
+
+	expectedSQL := "MERGE INTO t\n" +
+		"USING (\n" +
+		"  SELECT id\n" +
+		"  FROM s\n" +
+		") AS s\n" +
+		"ON t.id = s.id\n" +
+		"WHEN MATCHED THEN UPDATE SET a = ?,\n" +
+		"  b = ?\n" +
+		"WHEN NOT MATCHED THEN INSERT (id) VALUES (s.id)"
+	assert.Equal(t, expectedSQL, sql)
+}
+
+func TestMergeBuilderErrors(t *testing.T) {
+	base := Merge("t").Using("s", "").On("t.id = s.id")
+	tests := []MergeBuilder{
+		Merge("").Using("s", "").On("t.id = s.id").WhenMatched(nil).Delete(),
+		Merge("t").On("t.id = s.id").WhenMatched(nil).Delete(),
+		Merge("t").Using("s", "").WhenMatched(nil).Delete(),
+		base,
+		Merge("t").Using(Select("id").From("s"), "").On("t.id = s.id").WhenMatched(nil).Delete(),
+		base.WhenMatched(nil).Update(nil),
+		base.WhenNotMatched(nil).Insert(nil),
+		base.WhenNotMatched(nil).Insert([]string{"a", "b"}, 1),
+		base.WhenMatched(nil).Delete().Dialect(MySQL),
+		base.WhenMatched(nil).Delete().Dialect(SQLite),
+		base.WhenMatched(nil).DoNothing().Dialect(SQLServer),
+		base.WhenNotMatched(nil).DoNothing().Dialect(SQLServer),
+	}
+	for _, b := range tests {
+		_, _, err := b.ToSQL()
+		assert.Error(t, err)
+	}
+
+	_, _, err := base.WhenMatched(nil).Delete().Dialect(MySQL).ToSQL()
+	assert.EqualError(t, err, "merge statements are not supported for MySQL")
+
+	_, _, err = base.WhenNotMatched(nil).DoNothing().Dialect(SQLServer).ToSQL()
+	assert.Contains(t, err.Error(), "merge DO NOTHING is not supported for SQLServer")
+
+	_, _, err = base.WhenMatched(nil).Update(map[string]interface{}{"a": Select()}).ToSQL()
+	var buildErr *BuildError
+	assert.True(t, errors.As(err, &buildErr), "%v", err)
+	assert.Equal(t, "WHEN", buildErr.Clause)
+}
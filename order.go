@@ -0,0 +1,97 @@
+package sq
+
+import "fmt"
+
+// Order is the direction of an ORDER BY expression.
+type Order int
+
+const (
+	// Asc sorts in ascending order.
+	Asc Order = iota
+	// Desc sorts in descending order.
+	Desc
+)
+
+// Nulls is where NULLs are sorted by an ORDER BY expression.
+type Nulls int
+
+const (
+	// NullsDefault leaves NULLs where the database sorts them: after other
+	// values in ascending order on Postgres, before them on MySQL, SQLite and
+	// SQL Server.
+	NullsDefault Nulls = iota
+	// NullsFirst sorts NULLs before other values.
+	NullsFirst
+	// NullsLast sorts NULLs after other values.
+	NullsLast
+)
+
+// orderExpr is an ORDER BY expression with its direction and the position of
+// NULLs, which is emulated on databases without NULLS FIRST and NULLS LAST.
+type orderExpr struct {
+	expr  interface{}
+	order Order
+	nulls Nulls
+}
+
+func (o orderExpr) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(o)
+}
+
+func (o orderExpr) writeSQL(w *sqlWriter) {
+	var dir string
+	switch o.order {
+	case Asc:
+		dir = " ASC"
+	case Desc:
+		dir = " DESC"
+	default:
+		w.Fail(fmt.Errorf("unknown order %d", o.order))
+		return
+	}
+	if o.nulls < NullsDefault || o.nulls > NullsLast {
+		w.Fail(fmt.Errorf("unknown nulls order %d", o.nulls))
+		return
+	}
+
+	if o.nulls == NullsDefault {
+		part{pred: o.expr}.writeSQL(w)
+		w.WriteString(dir)
+		return
+	}
+
+	nullsFirst := o.nulls == NullsFirst
+	switch w.dialect {
+	case MySQL, SQLServer:
+		// NULLs sort before other values, so only the orders putting them
+		// elsewhere need a sort key
+		if nullsFirst != (o.order == Asc) {
+			if w.dialect == MySQL {
+				part{pred: o.expr}.writeSQL(w)
+				w.WriteString(" IS NULL")
+				if nullsFirst {
+					w.WriteString(" DESC")
+				}
+			} else {
+				w.WriteString("CASE WHEN ")
+				part{pred: o.expr}.writeSQL(w)
+				if nullsFirst {
+					w.WriteString(" IS NULL THEN 0 ELSE 1 END")
+				} else {
+					w.WriteString(" IS NULL THEN 1 ELSE 0 END")
+				}
+			}
+			w.WriteString(", ")
+		}
+		part{pred: o.expr}.writeSQL(w)
+		w.WriteString(dir)
+	default:
+		part{pred: o.expr}.writeSQL(w)
+		w.WriteString(dir)
+		if nullsFirst {
+			w.WriteString(" NULLS FIRST")
+		} else {
+			w.WriteString(" NULLS LAST")
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package sq
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectBuilderOrderByColumn(t *testing.T) {
+	b := Select("*").From("tasks").
+		OrderByColumn("due_at", Asc, NullsLast).
+		OrderByColumn("priority", Desc, NullsFirst).
+		OrderByColumn("id", Asc, NullsDefault)
+
+	tests := []struct {
+		dialect Dialect
+		sql     string
+	}{
+		{
+			Postgres,
+			"SELECT * FROM tasks ORDER BY due_at ASC NULLS LAST, priority DESC NULLS FIRST, id ASC",
+		},
+		{
+			SQLite,
+			"SELECT * FROM tasks ORDER BY due_at ASC NULLS LAST, priority DESC NULLS FIRST, id ASC",
+		},
+		{
+			MySQL,
+			"SELECT * FROM tasks ORDER BY due_at IS NULL, due_at ASC, priority IS NULL DESC, priority DESC, id ASC",
+		},
+		{
+			SQLServer,
+			"SELECT * FROM tasks ORDER BY CASE WHEN due_at IS NULL THEN 1 ELSE 0 END, due_at ASC, " +
+				"CASE WHEN priority IS NULL THEN 0 ELSE 1 END, priority DESC, id ASC",
+		},
+	}
+	for _, test := range tests {
+		sql, _, err := b.Dialect(test.dialect).ToSQL()
+		assert.NoError(t, err)
+		assert.Equal(t, test.sql, sql, "%s", test.dialect)
+	}
+}
+
+func TestSelectBuilderOrderByColumnNativeOrder(t *testing.T) {
+	// MySQL and SQL Server already sort NULLs first in ascending order
+	sql, _, err := Select("*").From("tasks").Dialect(MySQL).
+		OrderByColumn("due_at", Asc, NullsFirst).
+		OrderByColumn("priority", Desc, NullsLast).
+		ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM tasks ORDER BY due_at ASC, priority DESC", sql)
+}
+
+func TestSelectBuilderOrderByColumnExpr(t *testing.T) {
+	sql, args, err := StatementBuilder.Dialect(MySQL).
+		Select("*").From("users").
+		OrderByColumn(Expr("FIELD(role, ?, ?)", "admin", "user"), Desc, NullsFirst).
+		ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users ORDER BY FIELD(role, ?, ?) IS NULL DESC, FIELD(role, ?, ?) DESC", sql)
+	assert.Equal(t, []interface{}{"admin", "user", "admin", "user"}, args)
+}
+
+func TestSelectBuilderOrderByColumnSubqueryDialect(t *testing.T) {
+	sub := Select("id").From("tasks").OrderByColumn("due_at", Asc, NullsLast).Limit(1)
+	sql, _, err := Select("*").From("projects").Dialect(MySQL).
+		Where(Expr("id IN (?)", sub)).
+		OrderByColumn("name", Asc, NullsLast).
+		ToSQL()
+	assert.NoError(t, err)
+	expectedSQL := "SELECT * FROM projects WHERE id IN (SELECT id FROM tasks ORDER BY due_at ASC NULLS LAST LIMIT 1) " +
+		"ORDER BY name IS NULL, name ASC"
+	assert.Equal(t, expectedSQL, sql)
+}
+
+func TestSelectBuilderOrderByColumnErrors(t *testing.T) {
+	_, _, err := Select("*").From("t").OrderByColumn("a", Order(5), NullsDefault).ToSQL()
+	assert.Error(t, err)
+	_, _, err = Select("*").From("t").OrderByColumn("a", Asc, Nulls(5)).ToSQL()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderOrderByColumnJSON(t *testing.T) {
+	b := Select("*").From("tasks").Dialect(SQLServer).
+		OrderByColumn("due_at", Desc, NullsLast).
+		OrderByColumn(Lower("name"), Asc, NullsDefault)
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+
+	var decoded SelectBuilder
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+
+	expectedSQL, _, err := b.ToSQL()
+	assert.NoError(t, err)
+	sql, _, err := decoded.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSQL, sql)
+
+	var v struct {
+		Dialect string            `json:"dialect"`
+		OrderBy []json.RawMessage `json:"orderBy"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &v))
+	assert.Equal(t, "sqlserver", v.Dialect)
+	assert.JSONEq(t, `{"order":{"expr":"due_at","order":"desc","nulls":"last"}}`, string(v.OrderBy[0]))
+
+	_, err = JSONDecoder{}.DecodeSelect([]byte(`{"version":1,"columns":["id"],"orderBy":[{"order":{"expr":"id; --"}}]}`))
+	assert.True(t, errors.Is(err, ErrRawSQL), "%v", err)
+}
@@ -0,0 +1,170 @@
+package sq
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Pagination is the syntax of the LIMIT and OFFSET clauses of a statement.
+type Pagination int
+
+const (
+	// LimitOffset renders LIMIT n OFFSET m, as supported by Postgres, MySQL
+	// and SQLite. It is the default.
+	LimitOffset Pagination = iota
+	// OffsetFetch renders the standard OFFSET m ROWS FETCH NEXT n ROWS ONLY,
+	// as supported by Oracle, DB2 and Postgres. UPDATE and DELETE statements
+	// can't be limited.
+	OffsetFetch
+	// TopOffsetFetch renders the SQL Server syntax: SELECT TOP (n) if there is
+	// no offset, and OFFSET m ROWS FETCH NEXT n ROWS ONLY otherwise, which
+	// requires an ORDER BY clause. UPDATE and DELETE statements are limited
+	// with UPDATE TOP (n) and DELETE TOP (n), and can't be offset or ordered.
+	TopOffsetFetch
+)
+
+func (p Pagination) String() string {
+	switch p {
+	case LimitOffset:
+		return "LimitOffset"
+	case OffsetFetch:
+		return "OffsetFetch"
+	case TopOffsetFetch:
+		return "TopOffsetFetch"
+	default:
+		return fmt.Sprintf("Pagination(%d)", int(p))
+	}
+}
+
+// pagination is the LIMIT and OFFSET clauses of a statement.
+type pagination struct {
+	style    Pagination
+	stmt     string
+	limit    interface{}
+	offset   interface{}
+	bind     bool
+	percent  bool
+	withTies bool
+	ordered  bool
+}
+
+// check returns an error if the clauses can't be rendered in their style.
+func (p pagination) check() error {
+	if (p.percent || p.withTies) && p.limit == nil {
+		return fmt.Errorf("PERCENT and WITH TIES require a limit")
+	}
+	if p.withTies && !p.ordered {
+		return fmt.Errorf("WITH TIES requires an ORDER BY clause")
+	}
+
+	switch p.style {
+	case LimitOffset:
+		if p.percent || p.withTies {
+			return fmt.Errorf("PERCENT and WITH TIES are not supported by %s pagination", p.style)
+		}
+	case OffsetFetch:
+		if p.stmt != "SELECT" && (p.limit != nil || p.offset != nil) {
+			return fmt.Errorf("%s statements can't be limited or offset with %s pagination", p.stmt, p.style)
+		}
+	case TopOffsetFetch:
+		if p.stmt != "SELECT" {
+			if p.offset != nil {
+				return fmt.Errorf("%s statements can't be offset with %s pagination", p.stmt, p.style)
+			}
+			if p.limit != nil && p.ordered {
+				return fmt.Errorf("%s statements with TOP can't be ordered", p.stmt)
+			}
+		} else if p.offset != nil {
+			if !p.ordered {
+				return fmt.Errorf("OFFSET requires an ORDER BY clause with %s pagination", p.style)
+			}
+			if p.percent || p.withTies {
+				return fmt.Errorf("PERCENT and WITH TIES are not supported with an offset by %s pagination", p.style)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown pagination %s", p.style)
+	}
+	return nil
+}
+
+// top reports whether the limit is rendered as a TOP clause after the
+// statement keyword rather than at the end of the statement.
+func (p pagination) top() bool {
+	return p.style == TopOffsetFetch && p.limit != nil && (p.stmt != "SELECT" || p.offset == nil)
+}
+
+// writeTop writes the TOP clause, if any, followed by a space.
+func (p pagination) writeTop(w *sqlWriter) {
+	if !p.top() {
+		return
+	}
+	w.WriteString("TOP (")
+	p.writeValue(w, "LIMIT", p.limit)
+	w.WriteString(")")
+	if p.percent {
+		w.WriteString(" PERCENT")
+	}
+	if p.withTies {
+		w.WriteString(" WITH TIES")
+	}
+	w.WriteByte(' ')
+}
+
+// writeSQL writes the trailing LIMIT and OFFSET clauses, if any.
+func (p pagination) writeSQL(w *sqlWriter) {
+	switch p.style {
+	case LimitOffset:
+		if p.limit != nil {
+			w.Break()
+			w.WriteString("LIMIT ")
+			p.writeValue(w, "LIMIT", p.limit)
+		}
+		if p.offset != nil {
+			w.Break()
+			w.WriteString("OFFSET ")
+			p.writeValue(w, "OFFSET", p.offset)
+		}
+	case OffsetFetch, TopOffsetFetch:
+		if p.offset != nil {
+			w.Break()
+			w.WriteString("OFFSET ")
+			p.writeValue(w, "OFFSET", p.offset)
+			w.WriteString(" ROWS")
+		}
+		if p.limit != nil && !p.top() {
+			w.Break()
+			if p.offset != nil {
+				w.WriteString("FETCH NEXT ")
+			} else {
+				w.WriteString("FETCH FIRST ")
+			}
+			p.writeValue(w, "LIMIT", p.limit)
+			if p.percent {
+				w.WriteString(" PERCENT")
+			}
+			if p.withTies {
+				w.WriteString(" ROWS WITH TIES")
+			} else {
+				w.WriteString(" ROWS ONLY")
+			}
+		}
+	}
+}
+
+// writeValue writes the value of a LIMIT or OFFSET clause, which is either a
+// count, bound as an arg if bind is set, or a SQLizer.
+func (p pagination) writeValue(w *sqlWriter, clause string, v interface{}) {
+	switch v := v.(type) {
+	case uint64:
+		if p.bind {
+			w.WriteArg(v)
+		} else {
+			w.WriteString(strconv.FormatUint(v, 10))
+		}
+	case SQLizer:
+		w.WriteClause(clause, []SQLizer{v}, "")
+	default:
+		w.Fail(fmt.Errorf("expected uint64 or SQLizer for %s, not %T", clause, v))
+	}
+}
@@ -0,0 +1,193 @@
+package sq
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectBuilderPagination(t *testing.T) {
+	b := Select("*").From("users").OrderBy("id")
+	tests := []struct {
+		b   SelectBuilder
+		sql string
+	}{
+		{
+			b.Limit(10).Offset(20),
+			"SELECT * FROM users ORDER BY id LIMIT 10 OFFSET 20",
+		},
+		{
+			b.Limit(10).Offset(20).Pagination(OffsetFetch),
+			"SELECT * FROM users ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			b.Limit(10).Pagination(OffsetFetch).LimitPercent().LimitWithTies(),
+			"SELECT * FROM users ORDER BY id FETCH FIRST 10 PERCENT ROWS WITH TIES",
+		},
+		{
+			Select("*").From("users").Offset(5).Pagination(OffsetFetch),
+			"SELECT * FROM users OFFSET 5 ROWS",
+		},
+		{
+			Select("*").From("users").Limit(10).Pagination(TopOffsetFetch),
+			"SELECT TOP (10) * FROM users",
+		},
+		{
+			b.Distinct().Limit(10).Pagination(TopOffsetFetch).LimitPercent().LimitWithTies(),
+			"SELECT DISTINCT TOP (10) PERCENT WITH TIES * FROM users ORDER BY id",
+		},
+		{
+			b.Limit(10).Offset(20).Pagination(TopOffsetFetch),
+			"SELECT * FROM users ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+	}
+	for _, test := range tests {
+		sql, _, err := test.b.ToSQL()
+		assert.NoError(t, err)
+		assert.Equal(t, test.sql, sql)
+	}
+}
+
+func TestSelectBuilderPaginationErrors(t *testing.T) {
+	b := Select("*").From("users")
+	tests := []SelectBuilder{
+		b.OrderBy("id").Limit(10).LimitWithTies(),
+		b.OrderBy("id").Limit(10).LimitPercent(),
+		b.Limit(10).LimitWithTies().Pagination(OffsetFetch),
+		b.OrderBy("id").LimitPercent().Pagination(OffsetFetch),
+		b.Offset(20).Pagination(TopOffsetFetch),
+		b.OrderBy("id").Limit(10).Offset(20).LimitPercent().Pagination(TopOffsetFetch),
+		b.Limit(10).Pagination(Pagination(42)),
+	}
+	for _, test := range tests {
+		_, _, err := test.ToSQL()
+		assert.Error(t, err)
+	}
+
+	_, _, err := b.Offset(20).Pagination(TopOffsetFetch).ToSQL()
+	assert.EqualError(t, err, "OFFSET requires an ORDER BY clause with TopOffsetFetch pagination")
+}
+
+func TestUpdateDeletePagination(t *testing.T) {
+	sb := StatementBuilder.Pagination(TopOffsetFetch)
+
+	sql, args, err := sb.Update("users").Set("active", false).Where("id < ?", 100).Limit(10).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE TOP (10) users SET active = ? WHERE id < ?", sql)
+	assert.Equal(t, []interface{}{false, 100}, args)
+
+	sql, _, err = sb.Delete("users").Where("id < ?", 100).Limit(10).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE TOP (10) FROM users WHERE id < ?", sql)
+
+	_, _, err = sb.Delete("users").Limit(10).Offset(5).ToSQL()
+	assert.Error(t, err)
+	_, _, err = sb.Update("users").Set("a", 1).OrderBy("id").Limit(10).ToSQL()
+	assert.Error(t, err)
+	_, _, err = Delete("users").Limit(10).Pagination(OffsetFetch).ToSQL()
+	assert.Error(t, err)
+
+	// the pagination doesn't apply to INSERT statements
+	sql, _, err = sb.Insert("users").Values(1).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users VALUES (?)", sql)
+}
+
+func TestSelectBuilderPaginationJSON(t *testing.T) {
+	b := Select("*").From("users").OrderBy("id").Limit(10).Pagination(OffsetFetch).LimitWithTies()
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+
+	var decoded SelectBuilder
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	sql, _, err := decoded.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users ORDER BY id FETCH FIRST 10 ROWS WITH TIES", sql)
+
+	// without a pagination, the decoder's StatementBuilder one applies
+	sb := StatementBuilder.Pagination(TopOffsetFetch)
+	decoded, err = JSONDecoder{StatementBuilder: sb}.DecodeSelect([]byte(`{"version":1,"columns":["id"],"from":"users","limit":5}`))
+	assert.NoError(t, err)
+	sql, _, err = decoded.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT TOP (5) id FROM users", sql)
+}
+
+func TestBindLimits(t *testing.T) {
+	sql, args, err := Select("*").From("users").Where("active = ?", true).
+		Limit(10).Offset(20).BindLimits().PlaceholderFormat(Dollar).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE active = $1 LIMIT $2 OFFSET $3", sql)
+	assert.Equal(t, []interface{}{true, uint64(10), uint64(20)}, args)
+
+	sb := StatementBuilder.BindLimits().Pagination(TopOffsetFetch)
+
+	sql, args, err = sb.Select("*").From("users").Limit(10).Where("active = ?", true).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT TOP (?) * FROM users WHERE active = ?", sql)
+	assert.Equal(t, []interface{}{uint64(10), true}, args)
+
+	sql, args, err = sb.Select("*").From("users").OrderBy("id").Limit(10).Offset(20).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users ORDER BY id OFFSET ? ROWS FETCH NEXT ? ROWS ONLY", sql)
+	assert.Equal(t, []interface{}{uint64(20), uint64(10)}, args)
+
+	sql, args, err = sb.Update("users").Set("a", 1).Limit(5).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE TOP (?) users SET a = ?", sql)
+	assert.Equal(t, []interface{}{uint64(5), 1}, args)
+
+	sql, args, err = Delete("users").Where("a = ?", 1).Limit(5).Offset(2).BindLimits().ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users WHERE a = ? LIMIT ? OFFSET ?", sql)
+	assert.Equal(t, []interface{}{1, uint64(5), uint64(2)}, args)
+
+	// the options of the StatementBuilder don't apply to INSERT statements
+	_, _, err = sb.Insert("users").Values(1).ToSQL()
+	assert.NoError(t, err)
+}
+
+func TestLimitExpr(t *testing.T) {
+	sql, args, err := Select("*").From("users").
+		LimitExpr(Expr("? * ?", 2, 25)).
+		OffsetExpr(Greatest(Expr("? - ?", 10, 25), "0")).
+		ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users LIMIT ? * ? OFFSET GREATEST(? - ?, 0)", sql)
+	assert.Equal(t, []interface{}{2, 25, 10, 25}, args)
+
+	sql, args, err = Update("users").Set("a", 1).LimitExpr(Expr("?", 3)).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET a = ? LIMIT ?", sql)
+	assert.Equal(t, []interface{}{1, 3}, args)
+
+	sql, _, err = Delete("users").LimitExpr(Expr("5")).OffsetExpr(Expr("1")).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users LIMIT 5 OFFSET 1", sql)
+
+	_, _, err = Select("*").From("users").LimitExpr(Select().From("pages")).ToSQL()
+	var buildErr *BuildError
+	assert.True(t, errors.As(err, &buildErr), "%v", err)
+	assert.Equal(t, "LIMIT", buildErr.Clause)
+}
+
+func TestLimitsJSON(t *testing.T) {
+	b := Select("*").From("users").LimitExpr(Expr("? * ?", 2, 25)).Offset(20).BindLimits()
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+
+	decoded, err := JSONDecoder{AllowRaw: true}.DecodeSelect(data)
+	assert.NoError(t, err)
+	sql, args, err := decoded.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users LIMIT ? * ? OFFSET ?", sql)
+	assert.Equal(t, []interface{}{int64(2), int64(25), uint64(20)}, args)
+
+	var rejected SelectBuilder
+	err = json.Unmarshal(data, &rejected)
+	assert.True(t, errors.Is(err, ErrRawSQL), "%v", err)
+}
@@ -2,62 +2,37 @@ package sq
 
 import (
 	"fmt"
-	"io"
 )
 
 type part struct {
-	pred interface{}
-	args []interface{}
+	pred   interface{}
+	args   []interface{}
+	caller callSite
 }
 
 func newPart(pred interface{}, args ...interface{}) SQLizer {
-	return &part{pred, args}
+	p := &part{pred: pred, args: args}
+	p.caller.capture(1)
+	return p
 }
 
-func (p part) ToSQL() (sql string, args []interface{}, err error) {
+func (p *part) site() *callSite {
+	return &p.caller
+}
+
+func (p part) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(p)
+}
+
+func (p part) writeSQL(w *sqlWriter) {
 	switch pred := p.pred.(type) {
 	case nil:
 		// no-op
 	case SQLizer:
-		sql, args, err = nestedToSQL(pred)
+		w.WriteSQL(pred)
 	case string:
-		sql = pred
-		args = p.args
+		w.WriteRaw(pred, p.args, nil)
 	default:
-		err = fmt.Errorf("expected string or SQLizer, not %T", pred)
-	}
-	return
-}
-
-func nestedToSQL(s SQLizer) (string, []interface{}, error) {
-	if raw, ok := s.(rawSQLizer); ok {
-		return raw.toSQLRaw()
-	} else {
-		return s.ToSQL()
-	}
-}
-
-func appendToSQL(parts []SQLizer, w io.Writer, sep string, args []interface{}) ([]interface{}, error) {
-	for i, p := range parts {
-		partSQL, partArgs, err := nestedToSQL(p)
-		if err != nil {
-			return nil, err
-		} else if len(partSQL) == 0 {
-			continue
-		}
-
-		if i > 0 {
-			_, err := io.WriteString(w, sep)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		_, err = io.WriteString(w, partSQL)
-		if err != nil {
-			return nil, err
-		}
-		args = append(args, partArgs...)
+		w.Fail(fmt.Errorf("expected string or SQLizer, not %T", pred))
 	}
-	return args, nil
 }
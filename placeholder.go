@@ -1,8 +1,7 @@
 package sq
 
 import (
-	"bytes"
-	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -86,15 +85,21 @@ func Placeholders(count int) string {
 }
 
 func replacePositionalPlaceholders(sql, prefix string) (string, error) {
-	buf := &bytes.Buffer{}
+	if strings.IndexByte(sql, '?') == -1 {
+		return sql, nil
+	}
+
+	buf := &strings.Builder{}
+	buf.Grow(len(sql) + len(sql)/4)
+	var num [20]byte
 	i := 0
 	for {
-		p := strings.Index(sql, "?")
+		p := strings.IndexByte(sql, '?')
 		if p == -1 {
 			break
 		}
 
-		if len(sql[p:]) > 1 && sql[p:p+2] == "??" { // escape ?? => ?
+		if len(sql[p:]) > 1 && sql[p+1] == '?' { // escape ?? => ?
 			buf.WriteString(sql[:p])
 			buf.WriteString("?")
 			if len(sql[p:]) == 1 {
@@ -104,7 +109,8 @@ func replacePositionalPlaceholders(sql, prefix string) (string, error) {
 		} else {
 			i++
 			buf.WriteString(sql[:p])
-			fmt.Fprintf(buf, "%s%d", prefix, i)
+			buf.WriteString(prefix)
+			buf.Write(strconv.AppendInt(num[:0], int64(i), 10))
 			sql = sql[p+1:]
 		}
 	}
@@ -65,3 +65,125 @@ func BenchmarkPlaceholdersArray(b *testing.B) {
 func BenchmarkPlaceholdersStrings(b *testing.B) {
 	Placeholders(b.N)
 }
+
+func TestNestedBuilderPlaceholders(t *testing.T) {
+	formats := map[string]PlaceholderFormat{
+		"Question": Question,
+		"Dollar":   Dollar,
+		"Colon":    Colon,
+		"AtP":      AtP,
+	}
+
+	tests := []struct {
+		name  string
+		build func(f PlaceholderFormat) SQLizer
+		sql   string
+		args  []interface{}
+	}{
+		{
+			name: "SelectFromSelect",
+			build: func(f PlaceholderFormat) SQLizer {
+				sub := Select("a").From("b").Where("c = ?", 1).PlaceholderFormat(f)
+				return Select("a").FromSelect(sub, "s").Where("d = ?", 2).PlaceholderFormat(f)
+			},
+			sql:  "SELECT a FROM (SELECT a FROM b WHERE c = ?) AS s WHERE d = ?",
+			args: []interface{}{1, 2},
+		},
+		{
+			name: "SelectWhereExpr",
+			build: func(f PlaceholderFormat) SQLizer {
+				sub := Select("a").From("b").Where("c = ?", 1).PlaceholderFormat(f)
+				return Select("a").From("t").Where("x = ?", 0).Where(Expr("a IN (?)", sub)).PlaceholderFormat(f)
+			},
+			sql:  "SELECT a FROM t WHERE x = ? AND a IN (SELECT a FROM b WHERE c = ?)",
+			args: []interface{}{0, 1},
+		},
+		{
+			name: "SelectColumnConcat",
+			build: func(f PlaceholderFormat) SQLizer {
+				sub := Select("max(a)").From("b").Where("c = ?", 1).PlaceholderFormat(f)
+				return Select().Column("?", 0).Column(ConcatExpr("(", sub, ")")).PlaceholderFormat(f)
+			},
+			sql:  "SELECT ?, (SELECT max(a) FROM b WHERE c = ?)",
+			args: []interface{}{0, 1},
+		},
+		{
+			name: "UpdateSetSelect",
+			build: func(f PlaceholderFormat) SQLizer {
+				sub := Select("a").From("b").Where("c = ?", 2).PlaceholderFormat(f)
+				return Update("t").Set("x", 1).Set("y", sub).Where("z = ?", 3).PlaceholderFormat(f)
+			},
+			sql:  "UPDATE t SET x = ?, y = (SELECT a FROM b WHERE c = ?) WHERE z = ?",
+			args: []interface{}{1, 2, 3},
+		},
+		{
+			name: "InsertSelect",
+			build: func(f PlaceholderFormat) SQLizer {
+				sub := Select("a").From("b").Where("c = ?", 2).PlaceholderFormat(f)
+				return Insert("t").Prefix("WITH x AS (SELECT ?)", 1).Columns("a").Select(sub).PlaceholderFormat(f)
+			},
+			sql:  "WITH x AS (SELECT ?) INSERT INTO t (a) SELECT a FROM b WHERE c = ?",
+			args: []interface{}{1, 2},
+		},
+		{
+			name: "InsertValuesExpr",
+			build: func(f PlaceholderFormat) SQLizer {
+				sub := Select("a").From("b").Where("c = ?", 2).PlaceholderFormat(f)
+				return Insert("t").Values(1, Expr("(?)", sub), 3).PlaceholderFormat(f)
+			},
+			sql:  "INSERT INTO t VALUES (?,(SELECT a FROM b WHERE c = ?),?)",
+			args: []interface{}{1, 2, 3},
+		},
+		{
+			name: "SelectWithInsert",
+			build: func(f PlaceholderFormat) SQLizer {
+				ins := Insert("t").Values(1).Suffix("RETURNING id").PlaceholderFormat(f)
+				return Select("id").From("x").Where("y = ?", 2).
+					PrefixExpr(Expr("WITH x AS (?)", ins)).PlaceholderFormat(f)
+			},
+			sql:  "WITH x AS (INSERT INTO t VALUES (?) RETURNING id) SELECT id FROM x WHERE y = ?",
+			args: []interface{}{1, 2},
+		},
+		{
+			name: "SelectWithUpdate",
+			build: func(f PlaceholderFormat) SQLizer {
+				upd := Update("t").Set("a", 1).Suffix("RETURNING id").PlaceholderFormat(f)
+				return Select("id").From("x").Where("y = ?", 2).
+					PrefixExpr(Expr("WITH x AS (?)", upd)).PlaceholderFormat(f)
+			},
+			sql:  "WITH x AS (UPDATE t SET a = ? RETURNING id) SELECT id FROM x WHERE y = ?",
+			args: []interface{}{1, 2},
+		},
+		{
+			name: "DeleteWithDelete",
+			build: func(f PlaceholderFormat) SQLizer {
+				del := Delete("t").Where("a = ?", 1).Suffix("RETURNING id").PlaceholderFormat(f)
+				return Delete("u").Where("id IN (SELECT id FROM x) AND b = ?", 2).
+					PrefixExpr(Expr("WITH x AS (?)", del)).PlaceholderFormat(f)
+			},
+			sql:  "WITH x AS (DELETE FROM t WHERE a = ? RETURNING id) DELETE FROM u WHERE id IN (SELECT id FROM x) AND b = ?",
+			args: []interface{}{1, 2},
+		},
+		{
+			name: "CaseWithSelect",
+			build: func(f PlaceholderFormat) SQLizer {
+				sub := Select("a").From("b").Where("c = ?", 2).PlaceholderFormat(f)
+				c := Case().When(Expr("x = ?", 1), Alias(sub, "s"))
+				return Select().Column(c).Where("d = ?", 3).PlaceholderFormat(f)
+			},
+			sql:  "SELECT CASE WHEN x = ? THEN (SELECT a FROM b WHERE c = ?) AS s END WHERE d = ?",
+			args: []interface{}{1, 2, 3},
+		},
+	}
+
+	for _, test := range tests {
+		for name, f := range formats {
+			expectedSQL, _ := f.ReplacePlaceholders(test.sql)
+
+			sql, args, err := test.build(f).ToSQL()
+			assert.NoError(t, err, "%s/%s", test.name, name)
+			assert.Equal(t, expectedSQL, sql, "%s/%s", test.name, name)
+			assert.Equal(t, test.args, args, "%s/%s", test.name, name)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package sq
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// scope is a default predicate for every statement reading from, joining or
+// modifying table.
+type scope struct {
+	table  string
+	pred   interface{}
+	args   []interface{}
+	caller callSite
+}
+
+// on returns the predicate of the scope for a reference to its table, which
+// is named ref in the statement.
+func (s scope) on(ref string) SQLizer {
+	p := &wherePart{pred: s.pred, args: s.args, caller: s.caller}
+	switch pred := s.pred.(type) {
+	case func(string) SQLizer:
+		p.pred = pred(ref)
+	case map[string]interface{}:
+		p.pred = qualifyEq(ref, pred)
+	case Eq:
+		p.pred = qualifyEq(ref, pred)
+	case NotEq:
+		p.pred = NotEq(qualifyEq(ref, pred))
+	}
+	return p
+}
+
+// qualifyEq prefixes the unqualified column names in eq with "<ref>.".
+func qualifyEq(ref string, eq map[string]interface{}) Eq {
+	qualified := make(Eq, len(eq))
+	for col, val := range eq {
+		if !strings.Contains(col, ".") {
+			col = ref + "." + col
+		}
+		qualified[col] = val
+	}
+	return qualified
+}
+
+// tableRef is a table referenced by a statement, along with the name it is
+// referred to by in the rest of the statement.
+type tableRef struct {
+	table string
+	ref   string
+}
+
+// parseTableRefs parses the tables referenced by a FROM or JOIN target such as
+// "users", "users u", "users AS u" or "users u, orgs o". Subqueries are ignored.
+func parseTableRefs(s string) []tableRef {
+	var refs []tableRef
+	for _, item := range strings.Split(s, ",") {
+		fields := strings.Fields(item)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "(") {
+			continue
+		}
+		ref := tableRef{table: fields[0], ref: fields[0]}
+		if len(fields) > 2 && strings.EqualFold(fields[1], "AS") {
+			ref.ref = fields[2]
+		} else if len(fields) > 1 && !isTableHint(fields[1]) {
+			ref.ref = fields[1]
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// scopesFor returns the predicates of scopes applying to the tables in refs.
+func scopesFor(scopes []scope, refs []tableRef) []SQLizer {
+	var preds []SQLizer
+	for _, ref := range refs {
+		for _, s := range scopes {
+			if s.table == ref.table {
+				preds = append(preds, s.on(ref.ref))
+			}
+		}
+	}
+	return preds
+}
+
+// scopeTables returns the predicates of scopes applying to the tables of a FROM
+// clause or UPDATE/DELETE target.
+func scopeTables(tables string, scopes []scope) []SQLizer {
+	return scopesFor(scopes, parseTableRefs(tables))
+}
+
+// scopedJoin is an outer JOIN clause with the scopes of its joined table
+// applied: ANDed into the ON condition of a LEFT join, or, for the other outer
+// joins, filtering the joined table in a derived table.
+type scopedJoin struct {
+	join *part
+	// start and end are the bounds of the joined table in join.
+	start, end int
+	ref        string
+	// on is the start of the ON condition the scopes are ANDed into, or -1.
+	on    int
+	preds []SQLizer
+}
+
+// scopeJoin returns j with the scopes of its joined table applied. Scopes of
+// outer joins are applied by the join itself so that they don't turn into inner
+// joins; the rest are returned to be ANDed into WHERE.
+//
+// An ON condition only filters the table on the optional side of the join, so
+// the scopes of the preserved table of a RIGHT or FULL join can't go there.
+func scopeJoin(j SQLizer, scopes []scope) (SQLizer, []SQLizer) {
+	if je, ok := j.(*joinExpr); ok {
+		return je.scope(scopes)
+	}
+	p, ok := j.(*part)
+	if !ok {
+		return j, nil
+	}
+	join, ok := p.pred.(string)
+	if !ok {
+		return j, nil
+	}
+
+	start, end := joinTarget(join)
+	if start < 0 {
+		return j, nil
+	}
+
+	refs := parseTableRefs(join[start:end])
+	preds := scopesFor(scopes, refs)
+	if len(preds) == 0 {
+		return j, nil
+	}
+
+	upper := strings.ToUpper(join[:start])
+	left := strings.Contains(upper, "LEFT")
+	if !left && !strings.Contains(upper, "RIGHT") && !strings.Contains(upper, "FULL") {
+		return j, preds
+	}
+	if len(refs) != 1 {
+		return errorSQLizer{fmt.Errorf("can't apply the scopes of the outer join %q", join)}, nil
+	}
+	scoped := scopedJoin{join: p, start: start, end: end, ref: refs[0].ref, on: -1, preds: preds}
+	if m := joinOnRegexp.FindStringIndex(join[end:]); left && m != nil {
+		scoped.on = end + m[1]
+	}
+	return scoped, nil
+}
+
+var (
+	joinKeywordRegexp   = regexp.MustCompile(`(?i)\bJOIN\s+`)
+	joinConditionRegexp = regexp.MustCompile(`(?i)\s+(?:ON\s|USING\s*\()`)
+	joinOnRegexp        = regexp.MustCompile(`(?i)^\s+ON\s+`)
+)
+
+// joinTarget returns the bounds of the joined table of the JOIN clause join,
+// which ends at its ON or USING condition, or -1 if join has no JOIN keyword.
+func joinTarget(join string) (start, end int) {
+	m := joinKeywordRegexp.FindStringIndex(join)
+	if m == nil {
+		return -1, -1
+	}
+	start = m[1]
+	if c := joinConditionRegexp.FindStringIndex(join[start:]); c != nil {
+		return start, start + c[0]
+	}
+	return start, len(join)
+}
+
+func (j scopedJoin) site() *callSite {
+	return j.join.site()
+}
+
+func (j scopedJoin) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(j)
+}
+
+func (j scopedJoin) writeSQL(w *sqlWriter) {
+	join := j.join.pred.(string)
+
+	if j.on < 0 {
+		w.WriteString(join[:j.start])
+		w.WriteSQL(scopedTable{table: join[j.start:j.end], preds: j.preds})
+		w.WriteString(" AS ")
+		w.WriteString(j.ref)
+		w.WriteRaw(join[j.end:], j.join.args, nil)
+		return
+	}
+
+	w.WriteString(join[:j.on])
+	w.WriteByte('(')
+	w.WriteRaw(join[j.on:], j.join.args, nil)
+	w.WriteByte(')')
+	for _, pred := range j.preds {
+		w.WriteString(" AND ")
+		w.WriteSQL(pred)
+	}
+}
+
+// scopedTable is the joined table of an outer join which its ON condition, if
+// any, can't filter, filtered by the scopes of the table in a derived table,
+// e.g. "LEFT JOIN (SELECT * FROM t WHERE <preds>) AS t USING (id)".
+type scopedTable struct {
+	table string
+	preds []SQLizer
+}
+
+func (t scopedTable) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(t)
+}
+
+func (t scopedTable) writeSQL(w *sqlWriter) {
+	w.WriteString("(SELECT * FROM ")
+	w.WriteString(t.table)
+	w.WriteString(" WHERE ")
+	w.WriteConditions("WHERE", t.preds)
+	w.WriteByte(')')
+}
@@ -1,8 +1,9 @@
 package sq
 
 import (
-	"bytes"
 	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/lann/builder"
@@ -10,127 +11,374 @@ import (
 
 type selectData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
 	Prefixes          []SQLizer
 	Options           []string
+	DistinctOn        []SQLizer
 	Columns           []SQLizer
 	From              SQLizer
 	Joins             []SQLizer
 	WhereParts        []SQLizer
-	GroupBys          []string
+	GroupBys          []SQLizer
+	WithRollup        bool
 	HavingParts       []SQLizer
 	OrderByParts      []SQLizer
-	Limit             string
-	Offset            string
+	Limit             interface{}
+	Offset            interface{}
+	BindLimits        bool
+	Pagination        Pagination
+	LimitPercent      bool
+	LimitWithTies     bool
 	Suffixes          []SQLizer
+	Scopes            []scope
+	Unscoped          bool
+	Hints             []string
+	FromHints         []string
+	Comments          []map[string]string
+	Commenter         func() map[string]string
 }
 
 func (d *selectData) ToSQL() (sqlStr string, args []interface{}, err error) {
-	sqlStr, args, err = d.toSQLRaw()
+	sqlStr, args, err = rawToSQL(d)
 	if err != nil {
 		return
 	}
 
 	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr += sqlComment(d.Commenter, d.Comments)
 	return
 }
 
-func (d *selectData) toSQLRaw() (sqlStr string, args []interface{}, err error) {
+func (d *selectData) toSQLPretty(indent string) (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = prettyToSQL(d, indent)
+	if err != nil {
+		return
+	}
+
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr += sqlComment(d.Commenter, d.Comments)
+	return
+}
+
+func (d *selectData) writeSQL(w *sqlWriter) {
+	defer w.setDialect(d.Dialect)()
+
 	if len(d.Columns) == 0 {
-		err = fmt.Errorf("select statements must have at least one result column")
+		w.Fail(fmt.Errorf("select statements must have at least one result column"))
 		return
 	}
 
-	sql := &bytes.Buffer{}
+	page := d.pagination()
+	if err := page.check(); err != nil {
+		w.Fail(err)
+		return
+	}
 
 	if len(d.Prefixes) > 0 {
-		args, err = appendToSQL(d.Prefixes, sql, " ", args)
-		if err != nil {
-			return
-		}
-
-		sql.WriteString(" ")
+		w.WriteClause("PREFIX", d.Prefixes, " ")
+		w.Break()
 	}
 
-	sql.WriteString("SELECT ")
+	w.WriteString("SELECT ")
+	writeHints(w, d.Hints)
 
 	if len(d.Options) > 0 {
-		sql.WriteString(strings.Join(d.Options, " "))
-		sql.WriteString(" ")
+		w.WriteString(strings.Join(d.Options, " "))
+		w.WriteString(" ")
 	}
 
-	if len(d.Columns) > 0 {
-		args, err = appendToSQL(d.Columns, sql, ", ", args)
-		if err != nil {
+	if len(d.DistinctOn) > 0 {
+		if err := d.checkDistinctOn(); err != nil {
+			w.Fail(err)
 			return
 		}
+		w.WriteString("DISTINCT ON (")
+		w.WriteClause("DISTINCT ON", d.DistinctOn, ", ")
+		w.WriteString(") ")
+	}
+
+	page.writeTop(w)
+
+	if len(d.Columns) > 0 {
+		w.WriteClause("SELECT", d.Columns, ", ")
 	}
 
 	if d.From != nil {
-		sql.WriteString(" FROM ")
-		args, err = appendToSQL([]SQLizer{d.From}, sql, "", args)
-		if err != nil {
-			return
+		w.Break()
+		w.WriteString("FROM ")
+		w.WriteClause("FROM", []SQLizer{d.From}, "")
+		for _, hint := range d.FromHints {
+			w.WriteByte(' ')
+			w.WriteString(hint)
 		}
 	}
 
-	if len(d.Joins) > 0 {
-		sql.WriteString(" ")
-		args, err = appendToSQL(d.Joins, sql, " ", args)
-		if err != nil {
-			return
-		}
+	joins, whereParts := d.scoped()
+
+	if len(joins) > 0 {
+		w.Break()
+		w.WriteClause("JOIN", joins, w.BreakSep())
 	}
 
-	if len(d.WhereParts) > 0 {
-		sql.WriteString(" WHERE ")
-		args, err = appendToSQL(d.WhereParts, sql, " AND ", args)
-		if err != nil {
-			return
-		}
+	if len(whereParts) > 0 {
+		w.Break()
+		w.WriteString("WHERE ")
+		w.WriteConditions("WHERE", whereParts)
 	}
 
 	if len(d.GroupBys) > 0 {
-		sql.WriteString(" GROUP BY ")
-		sql.WriteString(strings.Join(d.GroupBys, ", "))
+		w.Break()
+		w.WriteString("GROUP BY ")
+		w.WriteClause("GROUP BY", d.GroupBys, ", ")
+		if d.WithRollup {
+			w.WriteString(" WITH ROLLUP")
+		}
 	}
 
 	if len(d.HavingParts) > 0 {
-		sql.WriteString(" HAVING ")
-		args, err = appendToSQL(d.HavingParts, sql, " AND ", args)
-		if err != nil {
-			return
-		}
+		w.Break()
+		w.WriteString("HAVING ")
+		w.WriteConditions("HAVING", d.HavingParts)
 	}
 
 	if len(d.OrderByParts) > 0 {
-		sql.WriteString(" ORDER BY ")
-		args, err = appendToSQL(d.OrderByParts, sql, ", ", args)
+		w.Break()
+		w.WriteString("ORDER BY ")
+		w.WriteClause("ORDER BY", d.OrderByParts, ", ")
+	}
+
+	page.writeSQL(w)
+
+	if len(d.Suffixes) > 0 {
+		w.Break()
+		w.WriteClause("SUFFIX", d.Suffixes, " ")
+	}
+}
+
+func (d *selectData) pagination() pagination {
+	return pagination{
+		style:    d.Pagination,
+		stmt:     "SELECT",
+		limit:    d.Limit,
+		offset:   d.Offset,
+		bind:     d.BindLimits,
+		percent:  d.LimitPercent,
+		withTies: d.LimitWithTies,
+		ordered:  len(d.OrderByParts) > 0,
+	}
+}
+
+// checkDistinctOn checks that the leading ORDER BY expressions are DISTINCT ON
+// expressions, as required by Postgres. Expressions are compared by their
+// text, so only the mismatches which are certain are reported: different
+// columns, or the same expression with different args. The rest are left to
+// the database.
+func (d *selectData) checkDistinctOn() error {
+	if len(d.OrderByParts) == 0 {
+		return nil
+	}
+
+	distinct, err := renderExprs(d.DistinctOn)
+	if err != nil {
+		return err
+	}
+	orderBys, err := renderExprs(d.OrderByParts)
+	if err != nil {
+		return err
+	}
+	if len(orderBys) > len(distinct) {
+		orderBys = orderBys[:len(distinct)]
+	}
+	for i := range orderBys {
+		orderBys[i].sql = orderDirectionRegexp.ReplaceAllString(orderBys[i].sql, "")
+	}
+
+	// Postgres adds the DISTINCT ON expressions missing from a shorter ORDER BY,
+	// so only the ORDER BY expressions have to be matched, in any order.
+	match := make([]int, len(distinct))
+	for i := range match {
+		match[i] = -1
+	}
+	var assign func(o int, seen []bool) bool
+	assign = func(o int, seen []bool) bool {
+		for i, e := range distinct {
+			if seen[i] || !orderBys[o].mayEqual(e) {
+				continue
+			}
+			seen[i] = true
+			if match[i] < 0 || assign(match[i], seen) {
+				match[i] = o
+				return true
+			}
+		}
+		return false
+	}
+	for o := range orderBys {
+		if assign(o, make([]bool, len(distinct))) {
+			continue
+		}
+		for _, e := range distinct {
+			if orderBys[o].normalizedSQL() == e.normalizedSQL() {
+				return fmt.Errorf("DISTINCT ON (%s) must match the leading ORDER BY expressions, "+
+					"but the args of %s differ", joinRendered(distinct), e.sql)
+			}
+		}
+		return fmt.Errorf("DISTINCT ON (%s) must match the leading ORDER BY expressions, got ORDER BY %s",
+			joinRendered(distinct), joinRendered(orderBys))
+	}
+	return nil
+}
+
+// orderDirectionRegexp matches the direction of an ORDER BY expression.
+var orderDirectionRegexp = regexp.MustCompile(`(?i)(\s+(ASC|DESC))?(\s+NULLS\s+(FIRST|LAST))?\s*$`)
+
+// renderedExpr is the SQL and args of an expression.
+type renderedExpr struct {
+	sql  string
+	args []interface{}
+}
+
+// renderExprs renders exprs, splitting the lists of expressions such as
+// "a, b DESC" given as a single one.
+func renderExprs(exprs []SQLizer) ([]renderedExpr, error) {
+	var rendered []renderedExpr
+	for _, e := range exprs {
+		w := &sqlWriter{}
+		w.WriteSQL(e)
+		sql, args, err := w.ToSQL()
 		if err != nil {
-			return
+			return nil, err
+		}
+		for _, item := range splitList(sql) {
+			n := countPlaceholders(item)
+			if n > len(args) {
+				n = len(args)
+			}
+			rendered = append(rendered, renderedExpr{sql: strings.TrimSpace(item), args: args[:n]})
+			args = args[n:]
 		}
 	}
+	return rendered, nil
+}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(d.Limit)
+// mayEqual reports whether e and other may be the same expression, i.e.
+// unless they are different columns or the same SQL with different args.
+func (e renderedExpr) mayEqual(other renderedExpr) bool {
+	sql, otherSQL := e.normalizedSQL(), other.normalizedSQL()
+	if sql == otherSQL {
+		return reflect.DeepEqual(e.args, other.args)
+	}
+	if !identPattern.MatchString(sql) || !identPattern.MatchString(otherSQL) {
+		return true
 	}
+	// a column qualified by its table and an unqualified one may be the same
+	names, otherNames := identSegmentRegexp.FindAllString(sql, -1), identSegmentRegexp.FindAllString(otherSQL, -1)
+	for len(names) > 0 && len(otherNames) > 0 {
+		if normalizeIdent(names[len(names)-1]) != normalizeIdent(otherNames[len(otherNames)-1]) {
+			return false
+		}
+		names, otherNames = names[:len(names)-1], otherNames[:len(otherNames)-1]
+	}
+	return true
+}
 
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
+// normalizedSQL returns the SQL of e with its whitespace collapsed.
+func (e renderedExpr) normalizedSQL() string {
+	return strings.Join(strings.Fields(e.sql), " ")
+}
+
+var identSegmentRegexp = regexp.MustCompile(identSegment)
+
+// normalizeIdent folds unquoted identifiers to lower case, as Postgres does,
+// and unquotes quoted ones, which are case-sensitive. Backquoted identifiers
+// aren't case-sensitive in MySQL, so they are folded too.
+func normalizeIdent(ident string) string {
+	switch {
+	case strings.HasPrefix(ident, `"`):
+		return strings.Trim(ident, `"`)
+	case strings.HasPrefix(ident, "`"):
+		return strings.ToLower(strings.Trim(ident, "`"))
+	}
+	return strings.ToLower(ident)
+}
+
+// splitList splits sql at the commas outside of parentheses and quotes.
+func splitList(sql string) []string {
+	var items []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, sql[start:i])
+			start = i + 1
+		}
 	}
+	return append(items, sql[start:])
+}
+
+// countPlaceholders counts the placeholders of sql outside of quotes.
+func countPlaceholders(sql string) int {
+	n := 0
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			n++
+		}
+	}
+	return n
+}
 
-	if len(d.Suffixes) > 0 {
-		sql.WriteString(" ")
+func joinRendered(exprs []renderedExpr) string {
+	sqls := make([]string, len(exprs))
+	for i, e := range exprs {
+		sqls[i] = e.sql
+	}
+	return strings.Join(sqls, ", ")
+}
 
-		args, err = appendToSQL(d.Suffixes, sql, " ", args)
-		if err != nil {
-			return
+// scoped returns the JOIN and WHERE parts of the query with the default scopes
+// of the tables it reads from applied.
+func (d *selectData) scoped() (joins, whereParts []SQLizer) {
+	if d.Unscoped || len(d.Scopes) == 0 {
+		return d.Joins, d.WhereParts
+	}
+
+	var preds []SQLizer
+	if p, ok := d.From.(*part); ok {
+		if from, ok := p.pred.(string); ok {
+			preds = scopeTables(from, d.Scopes)
 		}
 	}
 
-	sqlStr = sql.String()
-	return
+	joins = make([]SQLizer, len(d.Joins))
+	for i, join := range d.Joins {
+		var joinPreds []SQLizer
+		joins[i], joinPreds = scopeJoin(join, d.Scopes)
+		preds = append(preds, joinPreds...)
+	}
+
+	whereParts = append(d.WhereParts[:len(d.WhereParts):len(d.WhereParts)], preds...)
+	return joins, whereParts
 }
 
 // Builder
@@ -150,6 +398,12 @@ func (b SelectBuilder) PlaceholderFormat(f PlaceholderFormat) SelectBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(SelectBuilder)
 }
 
+// Dialect sets the dialect of the query, for the syntax which differs between
+// databases, e.g. how OrderByColumn sorts NULLs.
+func (b SelectBuilder) Dialect(d Dialect) SelectBuilder {
+	return builder.Set(b, "Dialect", d).(SelectBuilder)
+}
+
 // SQL methods
 
 // ToSQL builds the query into a SQL string and bound args.
@@ -158,9 +412,17 @@ func (b SelectBuilder) ToSQL() (string, []interface{}, error) {
 	return data.ToSQL()
 }
 
-func (b SelectBuilder) toSQLRaw() (string, []interface{}, error) {
+// ToSQLPretty is like ToSQL, but puts each clause of the query on its own line
+// and indents subqueries and continuation lines by indent, e.g. for logging.
+// The args are the same as those of ToSQL.
+func (b SelectBuilder) ToSQLPretty(indent string) (string, []interface{}, error) {
 	data := builder.GetStruct(b).(selectData)
-	return data.toSQLRaw()
+	return data.toSQLPretty(indent)
+}
+
+func (b SelectBuilder) writeSQL(w *sqlWriter) {
+	data := builder.GetStruct(b).(selectData)
+	data.writeSQL(w)
 }
 
 // MustSQL builds the query into a SQL string and bound args.
@@ -175,12 +437,12 @@ func (b SelectBuilder) MustSQL() (string, []interface{}) {
 
 // Prefix adds an expression to the beginning of the query.
 func (b SelectBuilder) Prefix(sql string, args ...interface{}) SelectBuilder {
-	return b.PrefixExpr(Expr(sql, args...))
+	return builder.Append(b, "Prefixes", newPart(Expr(sql, args...))).(SelectBuilder)
 }
 
 // PrefixExpr adds an expression to the very beginning of the query.
 func (b SelectBuilder) PrefixExpr(expr SQLizer) SelectBuilder {
-	return builder.Append(b, "Prefixes", expr).(SelectBuilder)
+	return builder.Append(b, "Prefixes", newPart(expr)).(SelectBuilder)
 }
 
 // Distinct adds a DISTINCT clause to the query.
@@ -193,6 +455,25 @@ func (b SelectBuilder) Options(options ...string) SelectBuilder {
 	return builder.Extend(b, "Options", options).(SelectBuilder)
 }
 
+// DistinctOn adds Postgres DISTINCT ON expressions to the query, which keeps
+// the first row of each group of rows with equal expressions. Each expression
+// is a column or a SQLizer. When the query is ordered, the leading ORDER BY
+// expressions must match them; ToSQL reports the mismatches it is certain of,
+// such as different columns.
+//
+// Ex:
+//
+//	Select("user_id", "created_at", "title").From("posts").
+//		DistinctOn("user_id").
+//		OrderBy("user_id", "created_at DESC")
+//	// SELECT DISTINCT ON (user_id) user_id, created_at, title FROM posts ORDER BY user_id, created_at DESC
+func (b SelectBuilder) DistinctOn(exprs ...interface{}) SelectBuilder {
+	for _, e := range exprs {
+		b = builder.Append(b, "DistinctOn", newPart(e)).(SelectBuilder)
+	}
+	return b
+}
+
 // Columns adds result columns to the query.
 func (b SelectBuilder) Columns(columns ...string) SelectBuilder {
 	parts := make([]interface{}, 0, len(columns))
@@ -218,11 +499,46 @@ func (b SelectBuilder) From(from string) SelectBuilder {
 
 // FromSelect sets a subquery into the FROM clause of the query.
 func (b SelectBuilder) FromSelect(from SelectBuilder, alias string) SelectBuilder {
-	// Prevent misnumbered parameters in nested selects (#183).
-	from = from.PlaceholderFormat(Question)
 	return builder.Set(b, "From", Alias(from, alias)).(SelectBuilder)
 }
 
+// Hint adds a MySQL or Oracle optimizer hint, which is rendered in a /*+ */
+// comment right after SELECT.
+//
+// Ex:
+//
+//	Select("*").From("users").Hint("MAX_EXECUTION_TIME(1000)")
+//	// SELECT /*+ MAX_EXECUTION_TIME(1000) */ * FROM users
+func (b SelectBuilder) Hint(hint string) SelectBuilder {
+	return builder.Append(b, "Hints", hint).(SelectBuilder)
+}
+
+// FromHint adds an index hint such as UseIndex("idx") or a table hint such as
+// TableHint("NOLOCK") after the table of the FROM clause and its alias.
+func (b SelectBuilder) FromHint(hint string) SelectBuilder {
+	return builder.Append(b, "FromHints", hint).(SelectBuilder)
+}
+
+// JoinHint adds an index or table hint after the joined table and its alias
+// of the last JOIN clause added to the query, which must be a string or added
+// by a join method such as JoinOn.
+//
+// Ex:
+//
+//	Select("*").From("users u").Join("orgs o ON o.id = u.org_id").JoinHint(ForceIndex("PRIMARY"))
+//	// SELECT * FROM users u JOIN orgs o FORCE INDEX (PRIMARY) ON o.id = u.org_id
+func (b SelectBuilder) JoinHint(hint string) SelectBuilder {
+	joins, _ := builder.Get(b, "Joins")
+	js, _ := joins.([]SQLizer)
+	if len(js) == 0 {
+		return builder.Append(b, "Joins", errorSQLizer{fmt.Errorf("join hint %q requires a JOIN clause", hint)}).(SelectBuilder)
+	}
+	js = append(js[:len(js)-1:len(js)-1], hintJoin(js[len(js)-1], hint))
+	// Extend rather than Set the joins so that further joins can be appended
+	b = builder.Delete(b, "Joins").(SelectBuilder)
+	return builder.Extend(b, "Joins", js).(SelectBuilder)
+}
+
 // JoinClause adds a join clause to the query.
 func (b SelectBuilder) JoinClause(pred interface{}, args ...interface{}) SelectBuilder {
 	return builder.Append(b, "Joins", newPart(pred, args...)).(SelectBuilder)
@@ -230,27 +546,32 @@ func (b SelectBuilder) JoinClause(pred interface{}, args ...interface{}) SelectB
 
 // Join adds a JOIN clause to the query.
 func (b SelectBuilder) Join(join string, rest ...interface{}) SelectBuilder {
-	return b.JoinClause("JOIN "+join, rest...)
+	return builder.Append(b, "Joins", newPart("JOIN "+join, rest...)).(SelectBuilder)
 }
 
 // LeftJoin adds a LEFT JOIN clause to the query.
 func (b SelectBuilder) LeftJoin(join string, rest ...interface{}) SelectBuilder {
-	return b.JoinClause("LEFT JOIN "+join, rest...)
+	return builder.Append(b, "Joins", newPart("LEFT JOIN "+join, rest...)).(SelectBuilder)
 }
 
 // RightJoin adds a RIGHT JOIN clause to the query.
 func (b SelectBuilder) RightJoin(join string, rest ...interface{}) SelectBuilder {
-	return b.JoinClause("RIGHT JOIN "+join, rest...)
+	return builder.Append(b, "Joins", newPart("RIGHT JOIN "+join, rest...)).(SelectBuilder)
 }
 
 // InnerJoin adds a INNER JOIN clause to the query.
 func (b SelectBuilder) InnerJoin(join string, rest ...interface{}) SelectBuilder {
-	return b.JoinClause("INNER JOIN "+join, rest...)
+	return builder.Append(b, "Joins", newPart("INNER JOIN "+join, rest...)).(SelectBuilder)
+}
+
+// FullJoin adds a FULL OUTER JOIN clause to the query.
+func (b SelectBuilder) FullJoin(join string, rest ...interface{}) SelectBuilder {
+	return builder.Append(b, "Joins", newPart("FULL OUTER JOIN "+join, rest...)).(SelectBuilder)
 }
 
 // CrossJoin adds a CROSS JOIN clause to the query.
 func (b SelectBuilder) CrossJoin(join string, rest ...interface{}) SelectBuilder {
-	return b.JoinClause("CROSS JOIN "+join, rest...)
+	return builder.Append(b, "Joins", newPart("CROSS JOIN "+join, rest...)).(SelectBuilder)
 }
 
 // Where adds an expression to the WHERE clause of the query.
@@ -274,15 +595,59 @@ func (b SelectBuilder) CrossJoin(join string, rest ...interface{}) SelectBuilder
 //
 // Where will panic if pred isn't any of the above types.
 func (b SelectBuilder) Where(pred interface{}, args ...interface{}) SelectBuilder {
+	return b.where(pred, args)
+}
+
+// WhereIf adds an expression to the WHERE clause of the query if cond is true.
+//
+// See Where.
+func (b SelectBuilder) WhereIf(cond bool, pred interface{}, args ...interface{}) SelectBuilder {
+	if !cond {
+		return b
+	}
+	return b.where(pred, args)
+}
+
+// where adds pred to the WHERE clause unless it is nil or empty, recording the
+// call to the method calling it.
+func (b SelectBuilder) where(pred interface{}, args []interface{}) SelectBuilder {
 	if pred == nil || pred == "" {
 		return b
 	}
-	return builder.Append(b, "WhereParts", newWherePart(pred, args...)).(SelectBuilder)
+	p := &wherePart{pred: pred, args: args}
+	p.caller.capture(1)
+	return builder.Append(b, "WhereParts", p).(SelectBuilder)
+}
+
+// Unscoped disables the default scopes of the StatementBuilderType the query
+// was created from.
+func (b SelectBuilder) Unscoped() SelectBuilder {
+	return builder.Set(b, "Unscoped", true).(SelectBuilder)
 }
 
 // GroupBy adds GROUP BY expressions to the query.
 func (b SelectBuilder) GroupBy(groupBys ...string) SelectBuilder {
-	return builder.Extend(b, "GroupBys", groupBys).(SelectBuilder)
+	for _, groupBy := range groupBys {
+		b = builder.Append(b, "GroupBys", newPart(groupBy)).(SelectBuilder)
+	}
+
+	return b
+}
+
+// GroupByClause adds a GROUP BY expression to the query, which can be a
+// string with args or a SQLizer such as Rollup, Cube or GroupingSets.
+//
+// Ex:
+//
+//	Select("date_trunc(?, created_at) AS d", "COUNT(*)").From("orders").
+//		GroupByClause("date_trunc(?, created_at)", "day")
+func (b SelectBuilder) GroupByClause(pred interface{}, args ...interface{}) SelectBuilder {
+	return builder.Append(b, "GroupBys", newPart(pred, args...)).(SelectBuilder)
+}
+
+// WithRollup adds the MySQL WITH ROLLUP modifier to the GROUP BY clause.
+func (b SelectBuilder) WithRollup() SelectBuilder {
+	return builder.Set(b, "WithRollup", true).(SelectBuilder)
 }
 
 // Having adds an expression to the HAVING clause of the query.
@@ -300,15 +665,30 @@ func (b SelectBuilder) OrderByClause(pred interface{}, args ...interface{}) Sele
 // OrderBy adds ORDER BY expressions to the query.
 func (b SelectBuilder) OrderBy(orderBys ...string) SelectBuilder {
 	for _, orderBy := range orderBys {
-		b = b.OrderByClause(orderBy)
+		b = builder.Append(b, "OrderByParts", newPart(orderBy)).(SelectBuilder)
 	}
 
 	return b
 }
 
+// OrderByColumn adds an ORDER BY expression sorted in the given order, with
+// NULLs sorted first or last. col is a column name or a SQLizer.
+//
+// NULLS FIRST and NULLS LAST are emulated on MySQL and SQL Server with a sort
+// key preceding col when the database doesn't already sort NULLs that way.
+//
+// Ex:
+//
+//	Select("*").From("tasks").OrderByColumn("due_at", Asc, NullsLast)
+//	// Postgres: SELECT * FROM tasks ORDER BY due_at ASC NULLS LAST
+//	// MySQL: SELECT * FROM tasks ORDER BY due_at IS NULL, due_at ASC
+func (b SelectBuilder) OrderByColumn(col interface{}, order Order, nulls Nulls) SelectBuilder {
+	return builder.Append(b, "OrderByParts", newPart(orderExpr{expr: col, order: order, nulls: nulls})).(SelectBuilder)
+}
+
 // Limit sets a LIMIT clause on the query.
 func (b SelectBuilder) Limit(limit uint64) SelectBuilder {
-	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(SelectBuilder)
+	return builder.Set(b, "Limit", limit).(SelectBuilder)
 }
 
 // RemoveLimit removes LIMIT clause.
@@ -318,7 +698,7 @@ func (b SelectBuilder) RemoveLimit() SelectBuilder {
 
 // Offset sets a OFFSET clause on the query.
 func (b SelectBuilder) Offset(offset uint64) SelectBuilder {
-	return builder.Set(b, "Offset", fmt.Sprintf("%d", offset)).(SelectBuilder)
+	return builder.Set(b, "Offset", offset).(SelectBuilder)
 }
 
 // RemoveOffset removes OFFSET clause.
@@ -326,12 +706,85 @@ func (b SelectBuilder) RemoveOffset() SelectBuilder {
 	return builder.Delete(b, "Offset").(SelectBuilder)
 }
 
+// LimitExpr sets a LIMIT clause on the query computed by expr, e.g.
+// Expr("? * ?", pages, pageSize).
+func (b SelectBuilder) LimitExpr(expr SQLizer) SelectBuilder {
+	return builder.Set(b, "Limit", newPart(expr)).(SelectBuilder)
+}
+
+// OffsetExpr sets a OFFSET clause on the query computed by expr.
+//
+// See LimitExpr.
+func (b SelectBuilder) OffsetExpr(expr SQLizer) SelectBuilder {
+	return builder.Set(b, "Offset", newPart(expr)).(SelectBuilder)
+}
+
+// BindLimits binds the values set by Limit and Offset as args instead of
+// writing them in the SQL, so that queries of every page are the same
+// statement for prepared statement caches and query statistics.
+//
+// Ex:
+//
+//	Select("*").From("users").Limit(10).Offset(20).BindLimits()
+//	// SELECT * FROM users LIMIT ? OFFSET ? [10 20]
+func (b SelectBuilder) BindLimits() SelectBuilder {
+	return builder.Set(b, "BindLimits", true).(SelectBuilder)
+}
+
+// Pagination sets the syntax of the LIMIT and OFFSET clauses of the query.
+//
+// Ex:
+//
+//	Select("*").From("users").OrderBy("id").Limit(10).Offset(20).Pagination(OffsetFetch)
+//	// SELECT * FROM users ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY
+func (b SelectBuilder) Pagination(p Pagination) SelectBuilder {
+	return builder.Set(b, "Pagination", p).(SelectBuilder)
+}
+
+// LimitPercent makes the limit a percentage of the rows, e.g. FETCH FIRST 10
+// PERCENT ROWS ONLY or TOP (10) PERCENT. It requires OffsetFetch or
+// TopOffsetFetch pagination.
+func (b SelectBuilder) LimitPercent() SelectBuilder {
+	return builder.Set(b, "LimitPercent", true).(SelectBuilder)
+}
+
+// LimitWithTies makes the limit include the rows which tie with the last one
+// in the ORDER BY clause, e.g. FETCH FIRST 10 ROWS WITH TIES or TOP (10) WITH
+// TIES. It requires OffsetFetch or TopOffsetFetch pagination.
+func (b SelectBuilder) LimitWithTies() SelectBuilder {
+	return builder.Set(b, "LimitWithTies", true).(SelectBuilder)
+}
+
 // Suffix adds an expression to the end of the query.
 func (b SelectBuilder) Suffix(sql string, args ...interface{}) SelectBuilder {
-	return b.SuffixExpr(Expr(sql, args...))
+	return builder.Append(b, "Suffixes", newPart(Expr(sql, args...))).(SelectBuilder)
 }
 
 // SuffixExpr adds an expression to the end of the query.
 func (b SelectBuilder) SuffixExpr(expr SQLizer) SelectBuilder {
-	return builder.Append(b, "Suffixes", expr).(SelectBuilder)
+	return builder.Append(b, "Suffixes", newPart(expr)).(SelectBuilder)
+}
+
+// Comment adds tags to the trailing comment of the statement, in the
+// sqlcommenter format, e.g. /*route='%2Fusers',traceparent='00-...'*/. Keys
+// and values are URL-encoded so they can't escape the comment. Tags added
+// later override the ones with the same key, including those of the
+// StatementBuilder's Commenter.
+func (b SelectBuilder) Comment(tags map[string]string) SelectBuilder {
+	return builder.Append(b, "Comments", tags).(SelectBuilder)
+}
+
+// Apply calls fn with the builder and returns its result, which allows reusing
+// functions that add clauses to a query.
+func (b SelectBuilder) Apply(fn func(SelectBuilder) SelectBuilder) SelectBuilder {
+	return fn(b)
+}
+
+// ApplyIf calls Apply if cond is true, and returns the builder unchanged
+// otherwise.
+func (b SelectBuilder) ApplyIf(cond bool, fn func(SelectBuilder) SelectBuilder) SelectBuilder {
+	if !cond {
+		return b
+	}
+	return b.Apply(fn)
 }
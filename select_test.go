@@ -2,6 +2,7 @@ package sq
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"testing"
@@ -369,3 +370,244 @@ func ExampleSelectBuilder_ToSQL() {
 		// scan...
 	}
 }
+
+func benchmarkSelectBuilder() SelectBuilder {
+	notBanned := Select("1").
+		From("bans").
+		Where("bans.user_id = users.id").
+		Prefix("NOT EXISTS (").
+		Suffix(")")
+
+	return Select("id", "name").
+		Column(Alias(Case("status").When("1", "'active'").Else("'inactive'"), "state")).
+		From("users").
+		Join("orgs ON orgs.id = users.org_id").
+		Where(Eq{"org_id": 42, "kind": []string{"a", "b", "c"}}).
+		Where(Or{Gt{"age": 18}, Expr("verified = ?", true)}).
+		Where(notBanned).
+		OrderBy("name").
+		Limit(10).
+		PlaceholderFormat(Dollar)
+}
+
+func BenchmarkSelectBuilderToSQL(b *testing.B) {
+	q := benchmarkSelectBuilder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := q.ToSQL(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSelectBuilderWhereIf(t *testing.T) {
+	var minAge *int
+	name := "a"
+
+	sql, args, err := Select("*").From("users").
+		WhereIf(minAge != nil, "age >= ?", minAge).
+		WhereIf(name != "", Eq{"name": name}).
+		ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE name = ?", sql)
+	assert.Equal(t, []interface{}{"a"}, args)
+
+	sql, _, err = Select("*").From("users").WhereIf(true, nil).WhereIf(true, "").ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users", sql)
+}
+
+func TestSelectBuilderApply(t *testing.T) {
+	active := func(b SelectBuilder) SelectBuilder {
+		return b.Where(Eq{"active": true})
+	}
+	paginate := func(b SelectBuilder) SelectBuilder {
+		return b.OrderBy("id").Limit(10)
+	}
+
+	sql, args, err := Select("*").From("users").
+		Apply(active).
+		ApplyIf(false, paginate).
+		ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE active = ?", sql)
+	assert.Equal(t, []interface{}{true}, args)
+
+	sql, _, err = Select("*").From("users").ApplyIf(true, paginate).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users ORDER BY id LIMIT 10", sql)
+}
+
+func TestSelectBuilderToSQLPretty(t *testing.T) {
+	sub := Select("id").From("orgs").Where(Eq{"active": true}).Where("kind = ?", "a")
+	b := Select("u.id", "u.name").
+		FromSelect(sub, "o").
+		Join("users u ON u.org_id = o.id").
+		LeftJoin("teams t ON t.id = u.team_id").
+		Where(And{Eq{"a": 1}, Or{Eq{"b": 2}, Eq{"c": 3}}}).
+		Where(Expr("u.id IN (?)", Select("id").From("admins").Where("level > ?", 2).Where("active"))).
+		GroupBy("u.id").
+		Having("COUNT(*) > ?", 3).
+		OrderBy("u.id").
+		Limit(10).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSQLPretty("  ")
+	assert.NoError(t, err)
+
+	expectedSQL := `SELECT u.id, u.name
+FROM (
+  SELECT id
+  FROM orgs
+  WHERE active = $1
+    AND kind = $2
+) AS o
+JOIN users u ON u.org_id = o.id
+LEFT JOIN teams t ON t.id = u.team_id
+WHERE (a = $3
+       AND (b = $4
+            OR c = $5))
+  AND u.id IN (SELECT id
+    FROM admins
+    WHERE level > $6
+      AND active)
+GROUP BY u.id
+HAVING COUNT(*) > $7
+ORDER BY u.id
+LIMIT 10`
+	assert.Equal(t, expectedSQL, sql)
+
+	_, expectedArgs, err := b.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestSelectBuilderToSQLPrettyError(t *testing.T) {
+	_, _, err := Select().From("x").ToSQLPretty("  ")
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderGroupByClause(t *testing.T) {
+	b := Select().
+		Column("date_trunc(?, created_at) AS d", "day").
+		Columns("region", "SUM(amount)").
+		From("orders").
+		Where("amount > ?", 0).
+		GroupByClause("date_trunc(?, created_at)", "day").
+		GroupByClause(Rollup("region", "city")).
+		Having("SUM(amount) > ?", 100).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSQL()
+	assert.NoError(t, err)
+	expectedSQL := "SELECT date_trunc($1, created_at) AS d, region, SUM(amount) FROM orders " +
+		"WHERE amount > $2 GROUP BY date_trunc($3, created_at), ROLLUP(region, city) HAVING SUM(amount) > $4"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{"day", 0, "day", 100}, args)
+}
+
+func TestSelectBuilderGroupingSets(t *testing.T) {
+	sql, _, err := Select("region", "city", "GROUPING(region, city) AS g", "SUM(amount)").
+		From("sales").
+		GroupByClause(GroupingSets(GroupingSet("region", "city"), "region", GroupingSet())).
+		ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT region, city, GROUPING(region, city) AS g, SUM(amount) FROM sales GROUP BY GROUPING SETS((region, city), region, ())", sql)
+
+	sql, _, err = Select("region", "SUM(amount)").From("sales").GroupBy("region", "city").WithRollup().ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT region, SUM(amount) FROM sales GROUP BY region, city WITH ROLLUP", sql)
+
+	sql, _, err = Select("a").From("t").GroupByClause(Cube("a", "b")).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t GROUP BY CUBE(a, b)", sql)
+}
+
+func TestSelectBuilderGroupByJSON(t *testing.T) {
+	b := Select("region").
+		Column(Sum("amount")).
+		From("sales").
+		GroupBy("region").
+		GroupByClause(Rollup("city", "store")).
+		WithRollup()
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+
+	var decoded SelectBuilder
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	sql, _, err := decoded.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT region, SUM(amount) FROM sales GROUP BY region, ROLLUP(city, store) WITH ROLLUP", sql)
+}
+
+func TestSelectBuilderDistinctOn(t *testing.T) {
+	sql, args, err := Select("user_id", "created_at", "title").
+		From("posts").
+		DistinctOn("user_id", Expr("date_trunc(?, created_at)", "day")).
+		Where("published = ?", true).
+		OrderBy("user_id").
+		OrderByClause("date_trunc(?, created_at) DESC", "day").
+		OrderBy("created_at DESC NULLS LAST").
+		PlaceholderFormat(Dollar).
+		ToSQL()
+	assert.NoError(t, err)
+
+	expectedSQL := "SELECT DISTINCT ON (user_id, date_trunc($1, created_at)) user_id, created_at, title " +
+		"FROM posts WHERE published = $2 ORDER BY user_id, date_trunc($3, created_at) DESC, created_at DESC NULLS LAST"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{"day", true, "day"}, args)
+
+	// the ORDER BY expressions can be in any order, fewer or missing
+	for _, b := range []SelectBuilder{
+		Select("*").From("t").DistinctOn("a", "b").OrderBy("b ASC", "a DESC", "c"),
+		Select("*").From("t").DistinctOn("a", "b").OrderBy("a"),
+		Select("*").From("t").DistinctOn("a", "b"),
+		Select("*").From("t").DistinctOn("user_id").OrderBy("user_id, created_at DESC"),
+		Select("*").From("t").DistinctOn("a, b").OrderBy("B", "a DESC, c"),
+		Select("*").From("users u").DistinctOn("u.user_id").OrderBy("user_id"),
+		Select("*").From("t").DistinctOn("a", Expr("lower(b)")).OrderBy("lower(t.b)", "a"),
+		Select("*").From("t").DistinctOn("user_id").OrderBy(`"user_id"`),
+		Select("*").From("t").DistinctOn(`"User_Id"`).OrderBy(`t."User_Id" DESC`),
+		Select("*").From("t").DistinctOn("user_id").OrderBy("`USER_ID`"),
+	} {
+		_, _, err := b.ToSQL()
+		assert.NoError(t, err)
+	}
+}
+
+func TestSelectBuilderDistinctOnMismatch(t *testing.T) {
+	tests := []SelectBuilder{
+		Select("*").From("t").DistinctOn("a").OrderBy("created_at DESC"),
+		Select("*").From("t").DistinctOn("a", "b").OrderBy("a", "c", "b"),
+		Select("*").From("t").DistinctOn(Expr("lower(?)", "x")).OrderByClause("lower(?)", "y"),
+		Select("*").From("t").DistinctOn("user_id").OrderBy("created_at, user_id"),
+		Select("*").From("t").DistinctOn("u.id").OrderBy("o.id"),
+		Select("*").From("t").DistinctOn("user_id").OrderBy(`"User_Id"`),
+	}
+	for _, b := range tests {
+		_, _, err := b.ToSQL()
+		assert.Error(t, err)
+	}
+
+	_, _, err := tests[0].ToSQL()
+	assert.EqualError(t, err, "DISTINCT ON (a) must match the leading ORDER BY expressions, got ORDER BY created_at")
+
+	_, _, err = tests[2].ToSQL()
+	assert.EqualError(t, err, "DISTINCT ON (lower(?)) must match the leading ORDER BY expressions, but the args of lower(?) differ")
+}
+
+func TestSelectBuilderDistinctOnJSON(t *testing.T) {
+	b := Select("user_id", "title").From("posts").DistinctOn("user_id").OrderBy("user_id", "created_at DESC")
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+
+	var decoded SelectBuilder
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	sql, _, err := decoded.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT DISTINCT ON (user_id) user_id, title FROM posts ORDER BY user_id, created_at DESC", sql)
+}
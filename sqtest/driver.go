@@ -0,0 +1,249 @@
+package sqtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+)
+
+// Statement is a statement executed through a Fake.
+type Statement struct {
+	SQL  string
+	Args []interface{}
+}
+
+// response is a scripted response to a statement.
+type response struct {
+	columns      []string
+	rows         [][]interface{}
+	lastInsertID int64
+	rowsAffected int64
+	err          error
+}
+
+// Fake is a database/sql driver which records the statements executed through
+// it and answers them with scripted responses, in order. Statements without a
+// scripted response return no rows and affect no rows.
+//
+// Transactions are recorded as the statements BEGIN, COMMIT and ROLLBACK.
+type Fake struct {
+	mu         sync.Mutex
+	statements []Statement
+	responses  []response
+}
+
+// NewFake returns a Fake and a *sql.DB using it.
+func NewFake() (*Fake, *sql.DB) {
+	f := &Fake{}
+	return f, sql.OpenDB(f)
+}
+
+// AddRows scripts the response to the next statement as rows with columns.
+func (f *Fake) AddRows(columns []string, rows ...[]interface{}) *Fake {
+	return f.add(response{columns: columns, rows: rows})
+}
+
+// AddResult scripts the response to the next statement as a result.
+func (f *Fake) AddResult(lastInsertID, rowsAffected int64) *Fake {
+	return f.add(response{lastInsertID: lastInsertID, rowsAffected: rowsAffected})
+}
+
+// AddError scripts the response to the next statement as err.
+func (f *Fake) AddError(err error) *Fake {
+	return f.add(response{err: err})
+}
+
+func (f *Fake) add(r response) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses = append(f.responses, r)
+	return f
+}
+
+// Statements returns the statements executed so far.
+func (f *Fake) Statements() []Statement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Statement(nil), f.statements...)
+}
+
+// Reset forgets the recorded statements and the remaining scripted responses.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statements = nil
+	f.responses = nil
+}
+
+// record records a statement and returns its scripted response.
+func (f *Fake) record(query string, args []driver.NamedValue) response {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stmt := Statement{SQL: query}
+	for _, arg := range args {
+		stmt.Args = append(stmt.Args, arg.Value)
+	}
+	f.statements = append(f.statements, stmt)
+
+	if len(f.responses) == 0 {
+		return response{}
+	}
+	r := f.responses[0]
+	f.responses = f.responses[1:]
+	return r
+}
+
+// Connect implements driver.Connector.
+func (f *Fake) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{fake: f}, nil
+}
+
+// Driver implements driver.Connector.
+func (f *Fake) Driver() driver.Driver {
+	return fakeDriver{fake: f}
+}
+
+type fakeDriver struct {
+	fake *Fake
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{fake: d.fake}, nil
+}
+
+type fakeConn struct {
+	fake *Fake
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	if r := c.fake.record("BEGIN", nil); r.err != nil {
+		return nil, r.err
+	}
+	return fakeTx{conn: c}, nil
+}
+
+// CheckNamedValue accepts args of any type so that they are recorded as they
+// were passed.
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r := c.fake.record(query, args)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return fakeResult{lastInsertID: r.lastInsertID, rowsAffected: r.rowsAffected}, nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r := c.fake.record(query, args)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &fakeRows{columns: r.columns, rows: r.rows}, nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (tx fakeTx) Commit() error {
+	return tx.conn.fake.record("COMMIT", nil).err
+}
+
+func (tx fakeTx) Rollback() error {
+	return tx.conn.fake.record("ROLLBACK", nil).err
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+// NumInput returns -1 as the number of placeholders isn't checked.
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *fakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *fakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+// CheckNamedValue accepts args of any type, like fakeConn.
+func (s *fakeStmt) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+type fakeResult struct {
+	lastInsertID, rowsAffected int64
+}
+
+func (r fakeResult) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r fakeResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]interface{}
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	for i, v := range r.rows[0] {
+		if i < len(dest) {
+			dest[i] = v
+		}
+	}
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,80 @@
+package sqtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tnychn/sq"
+)
+
+func TestFake(t *testing.T) {
+	fake, db := NewFake()
+	defer db.Close()
+
+	fake.AddRows([]string{"id", "name"}, []interface{}{int64(1), "a"}, []interface{}{int64(2), "b"})
+	fake.AddResult(3, 1)
+
+	sql, args := sq.Select("id", "name").From("users").Where("org_id = ?", 7).MustSQL()
+	rows, err := db.Query(sql, args...)
+	assert.NoError(t, err)
+
+	var names []string
+	for rows.Next() {
+		var (
+			id   int
+			name string
+		)
+		assert.NoError(t, rows.Scan(&id, &name))
+		names = append(names, name)
+	}
+	assert.NoError(t, rows.Err())
+	assert.NoError(t, rows.Close())
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	res, err := db.Exec("INSERT INTO users (name) VALUES (?)", "c")
+	assert.NoError(t, err)
+	id, _ := res.LastInsertId()
+	assert.Equal(t, int64(3), id)
+
+	res, err = db.Exec("DELETE FROM users")
+	assert.NoError(t, err)
+	n, _ := res.RowsAffected()
+	assert.Equal(t, int64(0), n)
+
+	expected := []Statement{
+		{SQL: "SELECT id, name FROM users WHERE org_id = ?", Args: []interface{}{7}},
+		{SQL: "INSERT INTO users (name) VALUES (?)", Args: []interface{}{"c"}},
+		{SQL: "DELETE FROM users"},
+	}
+	assert.Equal(t, expected, fake.Statements())
+
+	fake.Reset()
+	assert.Empty(t, fake.Statements())
+}
+
+func TestFakeTx(t *testing.T) {
+	fake, db := NewFake()
+	defer db.Close()
+
+	errBoom := errors.New("boom")
+	fake.AddResult(0, 0).AddError(errBoom)
+
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+	_, err = tx.Exec("UPDATE users SET a = ?", 1)
+	assert.Equal(t, errBoom, err)
+	assert.NoError(t, tx.Rollback())
+
+	stmt, err := db.Prepare("SELECT 1")
+	assert.NoError(t, err)
+	_, err = stmt.Query()
+	assert.NoError(t, err)
+	assert.NoError(t, stmt.Close())
+
+	var sqls []string
+	for _, s := range fake.Statements() {
+		sqls = append(sqls, s.SQL)
+	}
+	assert.Equal(t, []string{"BEGIN", "UPDATE users SET a = ?", "ROLLBACK", "SELECT 1"}, sqls)
+}
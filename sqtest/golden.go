@@ -0,0 +1,79 @@
+package sqtest
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tnychn/sq"
+)
+
+var update = flag.Bool("sqtest.update", false, "update the golden files of sqtest.AssertGolden")
+
+// GoldenDir is the directory golden files are stored in.
+var GoldenDir = "testdata"
+
+// prettySQLizer is implemented by the statement builders of sq.
+type prettySQLizer interface {
+	ToSQLPretty(indent string) (string, []interface{}, error)
+}
+
+// Golden returns the snapshot of s stored by AssertGolden: its SQL, pretty
+// printed if s is a statement builder, followed by a comment listing its args.
+func Golden(s sq.SQLizer) (string, error) {
+	var (
+		sql  string
+		args []interface{}
+		err  error
+	)
+	if p, ok := s.(prettySQLizer); ok {
+		sql, args, err = p.ToSQLPretty("  ")
+	} else {
+		sql, args, err = s.ToSQL()
+	}
+	if err != nil {
+		return "", err
+	}
+	return sql + "\n-- args: " + formatArgs(args) + "\n", nil
+}
+
+// AssertGolden checks that the snapshot of s returned by Golden matches the
+// golden file GoldenDir/name.golden, ignoring differences in whitespace.
+//
+// Run the tests with -sqtest.update to write the golden files instead.
+func AssertGolden(t testing.TB, s sq.SQLizer, name string) bool {
+	t.Helper()
+
+	actual, err := Golden(s)
+	if err != nil {
+		t.Errorf("ToSQL returned an error: %v", err)
+		return false
+	}
+
+	path := filepath.Join(GoldenDir, name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Errorf("creating golden file directory: %v", err)
+			return false
+		}
+		if err := ioutil.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Errorf("writing golden file: %v", err)
+			return false
+		}
+		return true
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("reading golden file (run with -sqtest.update to create it): %v", err)
+		return false
+	}
+	if Normalize(string(expected)) != Normalize(actual) {
+		t.Errorf("%s mismatch\nexpected:\n%s\nactual:\n%s", path, strings.TrimSpace(string(expected)), strings.TrimSpace(actual))
+		return false
+	}
+	return true
+}
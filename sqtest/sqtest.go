@@ -0,0 +1,135 @@
+// Package sqtest helps testing code which builds queries with sq: assertions
+// comparing SQL regardless of whitespace and placeholder format, golden files
+// for query snapshots and a fake database/sql driver.
+package sqtest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tnychn/sq"
+)
+
+// AssertSQL checks that s builds expectedSQL and expectedArgs. The SQL is
+// compared after normalizing both with Normalize, so it can be written with
+// any whitespace and placeholder format.
+func AssertSQL(t testing.TB, s sq.SQLizer, expectedSQL string, expectedArgs ...interface{}) bool {
+	t.Helper()
+
+	sql, args, err := s.ToSQL()
+	if err != nil {
+		t.Errorf("ToSQL returned an error: %v", err)
+		return false
+	}
+
+	ok := true
+	if Normalize(sql) != Normalize(expectedSQL) {
+		t.Errorf("SQL mismatch\nexpected: %s\n  actual: %s", Normalize(expectedSQL), Normalize(sql))
+		ok = false
+	}
+	if !argsEqual(args, expectedArgs) {
+		t.Errorf("args mismatch\nexpected: %s\n  actual: %s", formatArgs(expectedArgs), formatArgs(args))
+		ok = false
+	}
+	return ok
+}
+
+// Normalize returns sql with runs of whitespace collapsed into a single space,
+// whitespace around parentheses and commas removed and placeholders in the
+// Dollar, Colon and AtP formats replaced with "?". Quoted strings and
+// identifiers are left untouched.
+func Normalize(sql string) string {
+	b := &strings.Builder{}
+	space := false
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for j < len(sql) && sql[j] != c {
+				j++
+			}
+			if j < len(sql) {
+				j++
+			}
+			writeSpace(b, space)
+			b.WriteString(sql[i:j])
+			space = false
+			i = j - 1
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = b.Len() > 0
+		case c == '(' || c == ')' || c == ',':
+			b.WriteByte(c)
+			space = false
+			// drop the whitespace after it
+			for i+1 < len(sql) && isSpace(sql[i+1]) {
+				i++
+			}
+		default:
+			if n := placeholderLen(sql, i); n > 0 {
+				writeSpace(b, space)
+				b.WriteByte('?')
+				space = false
+				i += n - 1
+				continue
+			}
+			writeSpace(b, space)
+			b.WriteByte(c)
+			space = false
+		}
+	}
+	return b.String()
+}
+
+// writeSpace writes the pending space, if any. Pending spaces before
+// parentheses and commas are dropped by not calling it.
+func writeSpace(b *strings.Builder, space bool) {
+	if space {
+		b.WriteByte(' ')
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// placeholderLen returns the length of the numbered placeholder at sql[i:],
+// or 0 if there isn't one.
+func placeholderLen(sql string, i int) int {
+	var prefix string
+	switch {
+	case sql[i] == '$':
+		prefix = "$"
+	case sql[i] == ':' && (i == 0 || sql[i-1] != ':'):
+		prefix = ":"
+	case strings.HasPrefix(sql[i:], "@p"):
+		prefix = "@p"
+	default:
+		return 0
+	}
+	j := i + len(prefix)
+	for j < len(sql) && '0' <= sql[j] && sql[j] <= '9' {
+		j++
+	}
+	if j == i+len(prefix) {
+		return 0
+	}
+	return j - i
+}
+
+func argsEqual(args, expected []interface{}) bool {
+	if len(args) == 0 && len(expected) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(args, expected)
+}
+
+func formatArgs(args []interface{}) string {
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		strs[i] = fmt.Sprintf("%#v", arg)
+	}
+	return "[" + strings.Join(strs, ", ") + "]"
+}
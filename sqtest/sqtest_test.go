@@ -0,0 +1,82 @@
+package sqtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tnychn/sq"
+)
+
+// recorder is a testing.TB recording the errors reported to it.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{"SELECT  a,\n  b\nFROM t", "SELECT a,b FROM t"},
+		{"  WHERE ( a = $1 )\n\tAND b = $2 ", "WHERE(a = ?)AND b = ?"},
+		{"a = :1 AND b = @p2 AND c::int = ?", "a = ? AND b = ? AND c::int = ?"},
+		{"a = '$1  x' AND b = \"x  $2\"", "a = '$1  x' AND b = \"x  $2\""},
+		{"f( a , b )", "f(a,b)"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, Normalize(test.sql), test.sql)
+	}
+}
+
+func TestAssertSQL(t *testing.T) {
+	b := sq.Select("a", "b").From("t").Where("a = ?", 1).Where("b = ?", "x").PlaceholderFormat(sq.Dollar)
+
+	AssertSQL(t, b, `
+		SELECT a, b
+		FROM t
+		WHERE a = ? AND b = ?`, 1, "x")
+	AssertSQL(t, sq.Select("a").From("t"), "SELECT a FROM t")
+
+	r := &recorder{}
+	assert.False(t, AssertSQL(r, b, "SELECT a FROM t", 1, "x"))
+	assert.False(t, AssertSQL(r, b, "SELECT a, b FROM t WHERE a = $1 AND b = $2", 2, "x"))
+	assert.False(t, AssertSQL(r, sq.Select(), "SELECT"))
+	assert.Len(t, r.errors, 3)
+}
+
+func TestAssertGolden(t *testing.T) {
+	b := sq.Select("id", "name").
+		From("users").
+		Where(sq.Eq{"org_id": 1}).
+		Where("name LIKE ?", "a%").
+		OrderBy("name").
+		PlaceholderFormat(sq.Dollar)
+
+	AssertGolden(t, b, "select")
+	if *update {
+		return
+	}
+
+	r := &recorder{}
+	assert.False(t, AssertGolden(r, b.Where("x"), "select"))
+	assert.False(t, AssertGolden(r, b, "missing"))
+	assert.Len(t, r.errors, 2)
+}
+
+func TestGolden(t *testing.T) {
+	golden, err := Golden(sq.Select("a").From("t").Where("b = ?", "x"))
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a\nFROM t\nWHERE b = ?\n-- args: [\"x\"]\n", golden)
+
+	golden, err = Golden(sq.Eq{"a": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "a = ?\n-- args: [1]\n", golden)
+}
@@ -19,8 +19,11 @@ type SQLizer interface {
 
 // rawSQLizer is expected to do what SQLizer does, but without finalizing placeholders.
 // This is useful for nested queries.
+//
+// writeSQL renders the SQLizer straight into w, so that nested parts share a
+// single buffer and args slice instead of allocating their own.
 type rawSQLizer interface {
-	toSQLRaw() (string, []interface{}, error)
+	writeSQL(w *sqlWriter)
 }
 
 // DebugSQLizer calls ToSQL on s and shows the approximate SQL to be executed.
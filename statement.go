@@ -11,14 +11,17 @@ func (b StatementBuilderType) Select(columns ...string) SelectBuilder {
 }
 
 // Insert returns a InsertBuilder for this StatementBuilderType.
+//
+// WHERE expressions, scopes and the LIMIT and OFFSET options of this
+// StatementBuilderType don't apply to INSERT statements and are left out.
 func (b StatementBuilderType) Insert(into string) InsertBuilder {
-	return InsertBuilder(b).Into(into)
+	return InsertBuilder(b.forInsert()).Into(into)
 }
 
 // Replace returns a InsertBuilder for this StatementBuilderType with the
 // statement keyword set to "REPLACE".
 func (b StatementBuilderType) Replace(into string) InsertBuilder {
-	return InsertBuilder(b).statementKeyword("REPLACE").Into(into)
+	return InsertBuilder(b.forInsert()).statementKeyword("REPLACE").Into(into)
 }
 
 // Update returns a UpdateBuilder for this StatementBuilderType.
@@ -31,11 +34,61 @@ func (b StatementBuilderType) Delete(from string) DeleteBuilder {
 	return DeleteBuilder(b).From(from)
 }
 
+// BulkUpdate returns a BulkUpdateBuilder for this StatementBuilderType.
+//
+// The LIMIT and OFFSET options of this StatementBuilderType don't apply to
+// bulk updates and are left out.
+func (b StatementBuilderType) BulkUpdate(table string, keyColumns, setColumns []string, rows [][]interface{}) BulkUpdateBuilder {
+	return BulkUpdateBuilder(b.withoutLimits()).Table(table).Rows(keyColumns, setColumns, rows)
+}
+
+// Merge returns a MergeBuilder for this StatementBuilderType.
+//
+// Like for INSERT statements, WHERE expressions of this StatementBuilderType
+// are left out. Merging into a table with scopes is an error unless the query
+// is Unscoped.
+func (b StatementBuilderType) Merge(into string) MergeBuilder {
+	b = builder.Delete(b, "WhereParts").(StatementBuilderType)
+	return MergeBuilder(b.withoutLimits()).Into(into)
+}
+
 // PlaceholderFormat sets the PlaceholderFormat field for any child builders.
 func (b StatementBuilderType) PlaceholderFormat(f PlaceholderFormat) StatementBuilderType {
 	return builder.Set(b, "PlaceholderFormat", f).(StatementBuilderType)
 }
 
+// Commenter sets a function called each time a statement built from this
+// StatementBuilderType is rendered, returning the tags of its trailing
+// comment, e.g. the route and trace ID of the current request.
+//
+// See SelectBuilder.Comment.
+func (b StatementBuilderType) Commenter(fn func() map[string]string) StatementBuilderType {
+	return builder.Set(b, "Commenter", fn).(StatementBuilderType)
+}
+
+// Dialect sets the Dialect field for any child builders.
+//
+// See SelectBuilder.Dialect.
+func (b StatementBuilderType) Dialect(d Dialect) StatementBuilderType {
+	return builder.Set(b, "Dialect", d).(StatementBuilderType)
+}
+
+// Pagination sets the syntax of the LIMIT and OFFSET clauses for any child
+// builders, e.g. TopOffsetFetch for SQL Server.
+//
+// See SelectBuilder.Pagination.
+func (b StatementBuilderType) Pagination(p Pagination) StatementBuilderType {
+	return builder.Set(b, "Pagination", p).(StatementBuilderType)
+}
+
+// BindLimits binds the values of LIMIT and OFFSET clauses as args for any
+// child builders.
+//
+// See SelectBuilder.BindLimits.
+func (b StatementBuilderType) BindLimits() StatementBuilderType {
+	return builder.Set(b, "BindLimits", true).(StatementBuilderType)
+}
+
 // Where adds WHERE expressions to the query.
 //
 // See SelectBuilder.Where for more information.
@@ -43,6 +96,47 @@ func (b StatementBuilderType) Where(pred interface{}, args ...interface{}) State
 	return builder.Append(b, "WhereParts", newWherePart(pred, args...)).(StatementBuilderType)
 }
 
+// Scope adds a default scope to the StatementBuilderType: pred is ANDed into
+// the WHERE clause of every Select, Update and Delete statement built from it
+// which reads from, joins or modifies table. Use Unscoped on a statement to
+// bypass its scopes. Merge statements can't apply them and must be Unscoped to
+// merge into table.
+//
+// Tables are matched by name and the predicate is applied once per reference,
+// so a table joined twice under different aliases is scoped twice. Scopes of
+// tables joined by LEFT joins are ANDed into the ON condition instead so that
+// the join stays an outer join. Tables joined by RIGHT or FULL joins, which ON
+// doesn't filter, and by outer joins without ON, such as USING joins, are
+// joined filtered by their scopes instead, e.g.
+// "LEFT JOIN (SELECT * FROM t WHERE <scope>) AS t USING (id)".
+//
+// pred accepts the same types as SelectBuilder.Where. Unqualified column names
+// in map[string]interface{}, Eq and NotEq predicates are qualified with the
+// alias of the table (or its name if it has none); a func(alias string) SQLizer
+// is called with it to build the predicate, for example:
+//
+//	StatementBuilder.
+//		Scope("users", Eq{"tenant_id": tenantID}).
+//		Scope("users", func(alias string) SQLizer {
+//			return Expr(alias + ".deleted_at IS NULL")
+//		})
+func (b StatementBuilderType) Scope(table string, pred interface{}, args ...interface{}) StatementBuilderType {
+	s := scope{table: table, pred: pred, args: args}
+	s.caller.capture(0)
+	return builder.Append(b, "Scopes", s).(StatementBuilderType)
+}
+
+func (b StatementBuilderType) forInsert() StatementBuilderType {
+	b = builder.Delete(b, "WhereParts").(StatementBuilderType)
+	b = builder.Delete(b, "Scopes").(StatementBuilderType)
+	return b.withoutLimits()
+}
+
+func (b StatementBuilderType) withoutLimits() StatementBuilderType {
+	b = builder.Delete(b, "Pagination").(StatementBuilderType)
+	return builder.Delete(b, "BindLimits").(StatementBuilderType)
+}
+
 // StatementBuilder is a parent builder for other builders, e.g. SelectBuilder.
 var StatementBuilder = StatementBuilderType(builder.EmptyBuilder).PlaceholderFormat(Question)
 
@@ -82,6 +176,21 @@ func Delete(from string) DeleteBuilder {
 	return StatementBuilder.Delete(from)
 }
 
+// BulkUpdate returns a new BulkUpdateBuilder updating the rows of table
+// matching the keyColumns of rows.
+//
+// See BulkUpdateBuilder.Rows.
+func BulkUpdate(table string, keyColumns, setColumns []string, rows [][]interface{}) BulkUpdateBuilder {
+	return StatementBuilder.BulkUpdate(table, keyColumns, setColumns, rows)
+}
+
+// Merge returns a new MergeBuilder with the given target table name.
+//
+// See MergeBuilder.Into.
+func Merge(into string) MergeBuilder {
+	return StatementBuilder.Merge(into)
+}
+
 // Case returns a new CaseBuilder.
 // "what" represents case value.
 func Case(what ...interface{}) CaseBuilder {
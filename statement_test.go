@@ -18,3 +18,105 @@ func TestStatementBuilderWhere(t *testing.T) {
 	expectedArgs := []interface{}{1, 2}
 	assert.Equal(t, expectedArgs, args)
 }
+
+func TestStatementBuilderScope(t *testing.T) {
+	sb := StatementBuilder.
+		Scope("users", Eq{"tenant_id": 1}).
+		Scope("users", "users.deleted_at IS NULL").
+		Scope("orgs", func(alias string) SQLizer { return Expr(alias + ".deleted_at IS NULL") })
+
+	sql, args, err := sb.Select("*").From("users").Where("name = ?", "a").ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE name = ? AND users.tenant_id = ? AND users.deleted_at IS NULL", sql)
+	assert.Equal(t, []interface{}{"a", 1}, args)
+
+	sql, args, err = sb.Select("*").
+		From("users AS u").
+		Join("orgs o ON o.id = u.org_id").
+		LeftJoin("orgs parent ON parent.id = o.parent_id AND parent.kind = ?", "x").
+		PlaceholderFormat(Dollar).
+		ToSQL()
+	assert.NoError(t, err)
+	expectedSQL := "SELECT * FROM users AS u " +
+		"JOIN orgs o ON o.id = u.org_id " +
+		"LEFT JOIN orgs parent ON (parent.id = o.parent_id AND parent.kind = $1) AND parent.deleted_at IS NULL " +
+		"WHERE u.tenant_id = $2 AND users.deleted_at IS NULL AND o.deleted_at IS NULL"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{"x", 1}, args)
+
+	sql, args, err = sb.Select("*").
+		From("accounts a").
+		LeftJoin("users USING (account_id)").
+		RightJoin("orgs o USING (org_id)").
+		ToSQL()
+	assert.NoError(t, err)
+	expectedSQL = "SELECT * FROM accounts a " +
+		"LEFT JOIN (SELECT * FROM users WHERE users.tenant_id = ? AND users.deleted_at IS NULL) AS users USING (account_id) " +
+		"RIGHT JOIN (SELECT * FROM orgs o WHERE o.deleted_at IS NULL) AS o USING (org_id)"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	// ON doesn't filter the preserved table of RIGHT and FULL joins
+	sql, args, err = sb.Select("*").
+		From("accounts a").
+		RightJoin("orgs o ON o.id = a.org_id AND o.kind = ?", "x").
+		FullJoin("users\nON users.id = a.user_id").
+		ToSQL()
+	assert.NoError(t, err)
+	expectedSQL = "SELECT * FROM accounts a " +
+		"RIGHT JOIN (SELECT * FROM orgs o WHERE o.deleted_at IS NULL) AS o ON o.id = a.org_id AND o.kind = ? " +
+		"FULL OUTER JOIN (SELECT * FROM users WHERE users.tenant_id = ? AND users.deleted_at IS NULL) AS users\nON users.id = a.user_id"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{"x", 1}, args)
+
+	sql, _, err = sb.Select("*").
+		From("accounts a").
+		RightJoinOn("orgs", "o", Expr("o.id = a.org_id")).
+		FullJoinOn("users", "", Expr("users.id = a.user_id")).
+		ToSQL()
+	assert.NoError(t, err)
+	expectedSQL = "SELECT * FROM accounts a " +
+		"RIGHT JOIN (SELECT * FROM orgs AS o WHERE o.deleted_at IS NULL) AS o ON o.id = a.org_id " +
+		"FULL OUTER JOIN (SELECT * FROM users WHERE users.tenant_id = ? AND users.deleted_at IS NULL) AS users ON users.id = a.user_id"
+	assert.Equal(t, expectedSQL, sql)
+
+	sql, _, err = sb.Select("*").From("accounts a").LeftJoin("orgs o\n\tON o.id = a.org_id").ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM accounts a LEFT JOIN orgs o\n\tON (o.id = a.org_id) AND o.deleted_at IS NULL", sql)
+
+	sql, args, err = sb.Select("*").From("users").Unscoped().ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users", sql)
+	assert.Empty(t, args)
+
+	sql, _, err = sb.Select("*").From("accounts").ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM accounts", sql)
+}
+
+func TestStatementBuilderScopeUpdateDelete(t *testing.T) {
+	sb := StatementBuilder.Scope("users", Eq{"tenant_id": 1})
+
+	sql, args, err := sb.Update("users").Set("a", 2).Where("id = ?", 3).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET a = ? WHERE id = ? AND users.tenant_id = ?", sql)
+	assert.Equal(t, []interface{}{2, 3, 1}, args)
+
+	sql, args, err = sb.Delete("users").ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users WHERE users.tenant_id = ?", sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sql, _, err = sb.Delete("users").Unscoped().ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users", sql)
+}
+
+func TestStatementBuilderInsertIgnoresFilters(t *testing.T) {
+	sb := StatementBuilder.Where("x = ?", 1).Scope("users", Eq{"tenant_id": 1})
+
+	sql, args, err := sb.Insert("users").Values(2).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users VALUES (?)", sql)
+	assert.Equal(t, []interface{}{2}, args)
+}
@@ -1,7 +1,7 @@
 package sq
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -11,101 +11,189 @@ import (
 
 type updateData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
 	Prefixes          []SQLizer
 	Table             string
 	SetClauses        []setClause
 	WhereParts        []SQLizer
 	OrderBys          []string
-	Limit             string
-	Offset            string
+	Limit             interface{}
+	Offset            interface{}
+	BindLimits        bool
+	Pagination        Pagination
 	Suffixes          []SQLizer
+	Scopes            []scope
+	Unscoped          bool
+	Hints             []string
+	Comments          []map[string]string
+	Commenter         func() map[string]string
 }
 
 type setClause struct {
 	column string
 	value  interface{}
+	caller callSite
+}
+
+func (c *setClause) site() *callSite {
+	return &c.caller
+}
+
+// columnValue is a SET clause value computed from the column being set.
+type columnValue interface {
+	writeColumnSQL(w *sqlWriter, column string)
+}
+
+// arithExpr is a SET clause value adding to or subtracting from the column
+// being set.
+type arithExpr struct {
+	opr string
+	n   interface{}
+}
+
+// Increment can be used as a value in UpdateBuilder.Set and SetMap to add n to
+// the current value of the column, e.g. "counter = counter + ?".
+// n may be a SQLizer.
+func Increment(n interface{}) SQLizer {
+	return arithExpr{opr: "+", n: n}
+}
+
+// Decrement can be used as a value in UpdateBuilder.Set and SetMap to subtract
+// n from the current value of the column, e.g. "counter = counter - ?".
+// n may be a SQLizer.
+func Decrement(n interface{}) SQLizer {
+	return arithExpr{opr: "-", n: n}
+}
+
+// ToSQL fails, as the expression depends on the column being set.
+func (e arithExpr) ToSQL() (string, []interface{}, error) {
+	return "", nil, errors.New("Increment and Decrement can only be used as SET clause values")
+}
+
+func (e arithExpr) writeColumnSQL(w *sqlWriter, column string) {
+	w.WriteString(column)
+	w.WriteByte(' ')
+	w.WriteString(e.opr)
+	w.WriteByte(' ')
+	if s, ok := e.n.(SQLizer); ok {
+		w.WriteSQL(s)
+	} else {
+		w.WriteArg(e.n)
+	}
 }
 
 func (d *updateData) ToSQL() (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = rawToSQL(d)
+	if err != nil {
+		return
+	}
+
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr += sqlComment(d.Commenter, d.Comments)
+	return
+}
+
+func (d *updateData) toSQLPretty(indent string) (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = prettyToSQL(d, indent)
+	if err != nil {
+		return
+	}
+
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr += sqlComment(d.Commenter, d.Comments)
+	return
+}
+
+func (d *updateData) writeSQL(w *sqlWriter) {
+	defer w.setDialect(d.Dialect)()
+
 	if len(d.Table) == 0 {
-		err = fmt.Errorf("update statements must specify a table")
+		w.Fail(fmt.Errorf("update statements must specify a table"))
 		return
 	}
 	if len(d.SetClauses) == 0 {
-		err = fmt.Errorf("update statements must have at least one Set clause")
+		w.Fail(fmt.Errorf("update statements must have at least one Set clause"))
 		return
 	}
 
-	sql := &bytes.Buffer{}
+	page := pagination{
+		style:   d.Pagination,
+		stmt:    "UPDATE",
+		limit:   d.Limit,
+		offset:  d.Offset,
+		bind:    d.BindLimits,
+		ordered: len(d.OrderBys) > 0,
+	}
+	if err := page.check(); err != nil {
+		w.Fail(err)
+		return
+	}
 
 	if len(d.Prefixes) > 0 {
-		args, err = appendToSQL(d.Prefixes, sql, " ", args)
-		if err != nil {
-			return
-		}
+		w.WriteClause("PREFIX", d.Prefixes, " ")
+		w.Break()
+	}
 
-		sql.WriteString(" ")
-	}
-
-	sql.WriteString("UPDATE ")
-	sql.WriteString(d.Table)
-
-	sql.WriteString(" SET ")
-	setSQLs := make([]string, len(d.SetClauses))
-	for i, setClause := range d.SetClauses {
-		var valSQL string
-		if vs, ok := setClause.value.(SQLizer); ok {
-			vsql, vargs, err := vs.ToSQL()
-			if err != nil {
-				return "", nil, err
-			}
-			if _, ok := vs.(SelectBuilder); ok {
-				valSQL = fmt.Sprintf("(%s)", vsql)
-			} else {
-				valSQL = vsql
-			}
-			args = append(args, vargs...)
-		} else {
-			valSQL = "?"
-			args = append(args, setClause.value)
-		}
-		setSQLs[i] = fmt.Sprintf("%s = %s", setClause.column, valSQL)
+	w.WriteString("UPDATE ")
+	writeHints(w, d.Hints)
+	page.writeTop(w)
+	w.WriteString(d.Table)
+
+	w.Break()
+	writeSetClauses(w, d.SetClauses)
+
+	whereParts := d.WhereParts
+	if !d.Unscoped && len(d.Scopes) > 0 {
+		preds := scopeTables(d.Table, d.Scopes)
+		whereParts = append(whereParts[:len(whereParts):len(whereParts)], preds...)
 	}
-	sql.WriteString(strings.Join(setSQLs, ", "))
 
-	if len(d.WhereParts) > 0 {
-		sql.WriteString(" WHERE ")
-		args, err = appendToSQL(d.WhereParts, sql, " AND ", args)
-		if err != nil {
-			return
-		}
+	if len(whereParts) > 0 {
+		w.Break()
+		w.WriteString("WHERE ")
+		w.WriteConditions("WHERE", whereParts)
 	}
 
 	if len(d.OrderBys) > 0 {
-		sql.WriteString(" ORDER BY ")
-		sql.WriteString(strings.Join(d.OrderBys, ", "))
+		w.Break()
+		w.WriteString("ORDER BY ")
+		w.WriteString(strings.Join(d.OrderBys, ", "))
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(d.Limit)
-	}
+	page.writeSQL(w)
 
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
+	if len(d.Suffixes) > 0 {
+		w.Break()
+		w.WriteClause("SUFFIX", d.Suffixes, " ")
 	}
+}
 
-	if len(d.Suffixes) > 0 {
-		sql.WriteString(" ")
-		args, err = appendToSQL(d.Suffixes, sql, " ", args)
-		if err != nil {
-			return
+// writeSetClauses writes a SET clause.
+func writeSetClauses(w *sqlWriter, clauses []setClause) {
+	w.WriteString("SET ")
+	sep := w.ListSep(", ")
+	w.depth++
+	for i := range clauses {
+		setClause := &clauses[i]
+		m := w.mark()
+		if i > 0 {
+			w.WriteString(sep)
 		}
+		w.WriteString(setClause.column)
+		w.WriteString(" = ")
+		switch v := setClause.value.(type) {
+		case columnValue:
+			v.writeColumnSQL(w, setClause.column)
+		case SelectBuilder:
+			w.WriteSubquery(v)
+		case SQLizer:
+			w.WriteSQL(v)
+		default:
+			w.WriteArg(v)
+		}
+		w.recordPartError(m, "SET", i, setClause)
 	}
-
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
-	return
+	w.depth--
 }
 
 // Builder
@@ -124,6 +212,13 @@ func (b UpdateBuilder) PlaceholderFormat(f PlaceholderFormat) UpdateBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(UpdateBuilder)
 }
 
+// Dialect sets the dialect of the query.
+//
+// See SelectBuilder.Dialect.
+func (b UpdateBuilder) Dialect(d Dialect) UpdateBuilder {
+	return builder.Set(b, "Dialect", d).(UpdateBuilder)
+}
+
 // SQL methods
 
 // ToSQL builds the query into a SQL string and bound args.
@@ -132,6 +227,20 @@ func (b UpdateBuilder) ToSQL() (string, []interface{}, error) {
 	return data.ToSQL()
 }
 
+// ToSQLPretty is like ToSQL, but puts each SET assignment and each clause on
+// its own line.
+//
+// See SelectBuilder.ToSQLPretty for more information.
+func (b UpdateBuilder) ToSQLPretty(indent string) (string, []interface{}, error) {
+	data := builder.GetStruct(b).(updateData)
+	return data.toSQLPretty(indent)
+}
+
+func (b UpdateBuilder) writeSQL(w *sqlWriter) {
+	data := builder.GetStruct(b).(updateData)
+	data.writeSQL(w)
+}
+
 // MustSQL builds the query into a SQL string and bound args.
 // It panics if there are any errors.
 func (b UpdateBuilder) MustSQL() (string, []interface{}) {
@@ -144,12 +253,12 @@ func (b UpdateBuilder) MustSQL() (string, []interface{}) {
 
 // Prefix adds an expression to the beginning of the query.
 func (b UpdateBuilder) Prefix(sql string, args ...interface{}) UpdateBuilder {
-	return b.PrefixExpr(Expr(sql, args...))
+	return builder.Append(b, "Prefixes", newPart(Expr(sql, args...))).(UpdateBuilder)
 }
 
 // PrefixExpr adds an expression to the very beginning of the query.
 func (b UpdateBuilder) PrefixExpr(expr SQLizer) UpdateBuilder {
-	return builder.Append(b, "Prefixes", expr).(UpdateBuilder)
+	return builder.Append(b, "Prefixes", newPart(expr)).(UpdateBuilder)
 }
 
 // Table sets the table to be updated.
@@ -159,10 +268,53 @@ func (b UpdateBuilder) Table(table string) UpdateBuilder {
 
 // Set adds SET clauses to the query.
 func (b UpdateBuilder) Set(column string, value interface{}) UpdateBuilder {
-	return builder.Append(b, "SetClauses", setClause{column: column, value: value}).(UpdateBuilder)
+	return b.set(column, value)
+}
+
+// set adds a SET clause recording the call to the method calling it.
+func (b UpdateBuilder) set(column string, value interface{}) UpdateBuilder {
+	c := setClause{column: column, value: value}
+	c.caller.capture(1)
+	return builder.Append(b, "SetClauses", c).(UpdateBuilder)
+}
+
+// Incr adds a "column = column + n" SET clause to the query.
+func (b UpdateBuilder) Incr(column string, n interface{}) UpdateBuilder {
+	return b.set(column, Increment(n))
+}
+
+// Decr adds a "column = column - n" SET clause to the query.
+func (b UpdateBuilder) Decr(column string, n interface{}) UpdateBuilder {
+	return b.set(column, Decrement(n))
+}
+
+// SetExpr adds a SET clause setting column to a SQL expression.
+//
+// Ex:
+//
+//	SetExpr("balance", "balance * (1 + ?)", rate)
+func (b UpdateBuilder) SetExpr(column string, sql string, args ...interface{}) UpdateBuilder {
+	return b.set(column, Expr(sql, args...))
+}
+
+// SetNull adds a "column = NULL" SET clause to the query.
+func (b UpdateBuilder) SetNull(column string) UpdateBuilder {
+	return b.set(column, Expr("NULL"))
+}
+
+// SetDefault adds a "column = DEFAULT" SET clause to the query.
+func (b UpdateBuilder) SetDefault(column string) UpdateBuilder {
+	return b.set(column, Default)
+}
+
+// SetColumn adds a SET clause setting column to the value of another column.
+func (b UpdateBuilder) SetColumn(column string, other string) UpdateBuilder {
+	return b.set(column, Expr(other))
 }
 
 // SetMap is a convenience method which calls .Set for each key/value pair in clauses.
+//
+// Values can be Default, Increment and Decrement as well as any SQLizer.
 func (b UpdateBuilder) SetMap(clauses map[string]interface{}) UpdateBuilder {
 	keys := make([]string, len(clauses))
 	i := 0
@@ -173,7 +325,7 @@ func (b UpdateBuilder) SetMap(clauses map[string]interface{}) UpdateBuilder {
 	sort.Strings(keys)
 	for _, key := range keys {
 		val, _ := clauses[key]
-		b = b.Set(key, val)
+		b = b.set(key, val)
 	}
 	return b
 }
@@ -182,7 +334,34 @@ func (b UpdateBuilder) SetMap(clauses map[string]interface{}) UpdateBuilder {
 //
 // See SelectBuilder.Where for more information.
 func (b UpdateBuilder) Where(pred interface{}, args ...interface{}) UpdateBuilder {
-	return builder.Append(b, "WhereParts", newWherePart(pred, args...)).(UpdateBuilder)
+	return b.where(pred, args)
+}
+
+// WhereIf adds an expression to the WHERE clause of the query if cond is true.
+//
+// See Where.
+func (b UpdateBuilder) WhereIf(cond bool, pred interface{}, args ...interface{}) UpdateBuilder {
+	if !cond {
+		return b
+	}
+	return b.where(pred, args)
+}
+
+// where adds pred to the WHERE clause unless it is nil or empty, recording the
+// call to the method calling it.
+func (b UpdateBuilder) where(pred interface{}, args []interface{}) UpdateBuilder {
+	if pred == nil || pred == "" {
+		return b
+	}
+	p := &wherePart{pred: pred, args: args}
+	p.caller.capture(1)
+	return builder.Append(b, "WhereParts", p).(UpdateBuilder)
+}
+
+// Unscoped disables the default scopes of the StatementBuilderType the query
+// was created from.
+func (b UpdateBuilder) Unscoped() UpdateBuilder {
+	return builder.Set(b, "Unscoped", true).(UpdateBuilder)
 }
 
 // OrderBy adds ORDER BY expressions to the query.
@@ -192,20 +371,77 @@ func (b UpdateBuilder) OrderBy(orderBys ...string) UpdateBuilder {
 
 // Limit sets a LIMIT clause on the query.
 func (b UpdateBuilder) Limit(limit uint64) UpdateBuilder {
-	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(UpdateBuilder)
+	return builder.Set(b, "Limit", limit).(UpdateBuilder)
 }
 
 // Offset sets a OFFSET clause on the query.
 func (b UpdateBuilder) Offset(offset uint64) UpdateBuilder {
-	return builder.Set(b, "Offset", fmt.Sprintf("%d", offset)).(UpdateBuilder)
+	return builder.Set(b, "Offset", offset).(UpdateBuilder)
+}
+
+// LimitExpr sets a LIMIT clause on the query computed by expr.
+//
+// See SelectBuilder.LimitExpr.
+func (b UpdateBuilder) LimitExpr(expr SQLizer) UpdateBuilder {
+	return builder.Set(b, "Limit", newPart(expr)).(UpdateBuilder)
+}
+
+// OffsetExpr sets a OFFSET clause on the query computed by expr.
+//
+// See SelectBuilder.LimitExpr.
+func (b UpdateBuilder) OffsetExpr(expr SQLizer) UpdateBuilder {
+	return builder.Set(b, "Offset", newPart(expr)).(UpdateBuilder)
+}
+
+// BindLimits binds the values set by Limit and Offset as args instead of
+// writing them in the SQL.
+//
+// See SelectBuilder.BindLimits.
+func (b UpdateBuilder) BindLimits() UpdateBuilder {
+	return builder.Set(b, "BindLimits", true).(UpdateBuilder)
+}
+
+// Pagination sets the syntax of the LIMIT and OFFSET clauses of the query.
+//
+// See SelectBuilder.Pagination.
+func (b UpdateBuilder) Pagination(p Pagination) UpdateBuilder {
+	return builder.Set(b, "Pagination", p).(UpdateBuilder)
 }
 
 // Suffix adds an expression to the end of the query.
 func (b UpdateBuilder) Suffix(sql string, args ...interface{}) UpdateBuilder {
-	return b.SuffixExpr(Expr(sql, args...))
+	return builder.Append(b, "Suffixes", newPart(Expr(sql, args...))).(UpdateBuilder)
 }
 
 // SuffixExpr adds an expression to the end of the query.
 func (b UpdateBuilder) SuffixExpr(expr SQLizer) UpdateBuilder {
-	return builder.Append(b, "Suffixes", expr).(UpdateBuilder)
+	return builder.Append(b, "Suffixes", newPart(expr)).(UpdateBuilder)
+}
+
+// Hint adds a MySQL or Oracle optimizer hint, which is rendered in a /*+ */
+// comment right after UPDATE.
+func (b UpdateBuilder) Hint(hint string) UpdateBuilder {
+	return builder.Append(b, "Hints", hint).(UpdateBuilder)
+}
+
+// Comment adds tags to the trailing comment of the statement.
+//
+// See SelectBuilder.Comment for more information.
+func (b UpdateBuilder) Comment(tags map[string]string) UpdateBuilder {
+	return builder.Append(b, "Comments", tags).(UpdateBuilder)
+}
+
+// Apply calls fn with the builder and returns its result, which allows reusing
+// functions that add clauses to a query.
+func (b UpdateBuilder) Apply(fn func(UpdateBuilder) UpdateBuilder) UpdateBuilder {
+	return fn(b)
+}
+
+// ApplyIf calls Apply if cond is true, and returns the builder unchanged
+// otherwise.
+func (b UpdateBuilder) ApplyIf(cond bool, fn func(UpdateBuilder) UpdateBuilder) UpdateBuilder {
+	if !cond {
+		return b
+	}
+	return b.Apply(fn)
 }
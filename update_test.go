@@ -65,3 +65,88 @@ func TestUpdateBuilderPlaceholders(t *testing.T) {
 	sql, _, _ = b.PlaceholderFormat(Dollar).ToSQL()
 	assert.Equal(t, "UPDATE test SET x = $1, y = $2", sql)
 }
+
+func TestUpdateBuilderWhereIfApply(t *testing.T) {
+	touch := func(b UpdateBuilder) UpdateBuilder {
+		return b.Set("updated_at", Expr("NOW()"))
+	}
+
+	sql, args, err := Update("t").Set("a", 1).
+		WhereIf(true, "b = ?", 2).
+		WhereIf(false, "c = ?", 3).
+		ApplyIf(true, touch).
+		ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE t SET a = ?, updated_at = NOW() WHERE b = ?", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+
+	sql, _, err = Update("t").Set("a", 1).WhereIf(true, nil).WhereIf(true, "").Where("").ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE t SET a = ?", sql)
+}
+
+func TestUpdateBuilderSetHelpers(t *testing.T) {
+	sql, args, err := Update("accounts").
+		Incr("counter", 1).
+		Decr("balance", Expr("? * 2", 5)).
+		SetExpr("rate", "rate * (1 + ?)", 0.1).
+		SetNull("note").
+		SetDefault("status").
+		SetColumn("prev_balance", "balance").
+		Where("id = ?", 7).
+		PlaceholderFormat(Dollar).
+		ToSQL()
+	assert.NoError(t, err)
+
+	expectedSQL := "UPDATE accounts SET counter = counter + $1, balance = balance - $2 * 2, " +
+		"rate = rate * (1 + $3), note = NULL, status = DEFAULT, prev_balance = balance WHERE id = $4"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{1, 5, 0.1, 7}, args)
+}
+
+func TestUpdateBuilderSetMapMarkers(t *testing.T) {
+	sql, args, err := Update("items").SetMap(map[string]interface{}{
+		"a": Increment(2),
+		"b": Decrement(3),
+		"c": Default,
+		"d": Expr("e"),
+		"f": 4,
+	}).ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE items SET a = a + ?, b = b - ?, c = DEFAULT, d = e, f = ?", sql)
+	assert.Equal(t, []interface{}{2, 3, 4}, args)
+
+	// the markers are SQLizers, but Increment and Decrement need a column
+	var incr SQLizer = Increment(1)
+	_, _, err = incr.ToSQL()
+	assert.Error(t, err)
+	_, _, err = Insert("items").Values(Decrement(1)).ToSQL()
+	assert.Error(t, err)
+
+	sql, _, err = Default.ToSQL()
+	assert.NoError(t, err)
+	assert.Equal(t, "DEFAULT", sql)
+}
+
+func TestUpdateBuilderToSQLPretty(t *testing.T) {
+	b := Update("t").
+		Set("a", 1).
+		Set("b", Select("x").From("y").Where("z = ?", 2)).
+		Where("id = ?", 3).
+		Where("deleted_at IS NULL")
+
+	sql, args, err := b.ToSQLPretty("\t")
+	assert.NoError(t, err)
+
+	expectedSQL := "UPDATE t\n" +
+		"SET a = ?,\n" +
+		"\tb = (\n" +
+		"\t\tSELECT x\n" +
+		"\t\tFROM y\n" +
+		"\t\tWHERE z = ?\n" +
+		"\t)\n" +
+		"WHERE id = ?\n" +
+		"\tAND deleted_at IS NULL"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{1, 2, 3}, args)
+}
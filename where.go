@@ -7,24 +7,30 @@ import (
 type wherePart part
 
 func newWherePart(pred interface{}, args ...interface{}) SQLizer {
-	return &wherePart{pred: pred, args: args}
+	p := &wherePart{pred: pred, args: args}
+	p.caller.capture(1)
+	return p
 }
 
-func (p wherePart) ToSQL() (sql string, args []interface{}, err error) {
+func (p *wherePart) site() *callSite {
+	return &p.caller
+}
+
+func (p wherePart) ToSQL() (string, []interface{}, error) {
+	return rawToSQL(p)
+}
+
+func (p wherePart) writeSQL(w *sqlWriter) {
 	switch pred := p.pred.(type) {
 	case nil:
 		// no-op
-	case rawSQLizer:
-		return pred.toSQLRaw()
 	case SQLizer:
-		return pred.ToSQL()
+		w.WriteSQL(pred)
 	case map[string]interface{}:
-		return Eq(pred).ToSQL()
+		Eq(pred).writeSQL(w)
 	case string:
-		sql = pred
-		args = p.args
+		w.WriteRaw(pred, p.args, nil)
 	default:
-		err = fmt.Errorf("expected string-keyed map or string, not %T", pred)
+		w.Fail(fmt.Errorf("expected string-keyed map or string, not %T", pred))
 	}
-	return
 }
@@ -1,28 +1,29 @@
 package sq
 
 import (
-	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestWherePartsAppendToSQL(t *testing.T) {
+func TestWherePartsWriteSQL(t *testing.T) {
 	parts := []SQLizer{
 		newWherePart("x = ?", 1),
 		newWherePart(nil),
 		newWherePart(Eq{"y": 2}),
 	}
-	sql := &bytes.Buffer{}
-	args, _ := appendToSQL(parts, sql, " AND ", []interface{}{})
-	assert.Equal(t, "x = ? AND y = ?", sql.String())
+	w := &sqlWriter{}
+	w.WriteParts(parts, " AND ")
+	sql, args, _ := w.ToSQL()
+	assert.Equal(t, "x = ? AND y = ?", sql)
 	assert.Equal(t, []interface{}{1, 2}, args)
 }
 
-func TestWherePartsAppendToSQLErr(t *testing.T) {
+func TestWherePartsWriteSQLErr(t *testing.T) {
 	parts := []SQLizer{newWherePart(1)}
-	_, err := appendToSQL(parts, &bytes.Buffer{}, "", []interface{}{})
-	assert.Error(t, err)
+	w := &sqlWriter{}
+	w.WriteParts(parts, "")
+	assert.Error(t, w.Err())
 }
 
 func TestWherePartNil(t *testing.T) {
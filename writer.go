@@ -0,0 +1,298 @@
+package sq
+
+import (
+	"bytes"
+	"strings"
+)
+
+// sqlWriter accumulates the SQL and bound args of a query while its parts are
+// written into it one by one, so that nested parts don't have to allocate
+// intermediate strings and arg slices.
+//
+// The first error encountered is kept and every subsequent write becomes a
+// no-op, which allows writing many parts without constant checks for errors.
+//
+// Errors of parts written as part of a clause are collected instead, so that
+// every problem of a query can be reported at once.
+type sqlWriter struct {
+	bytes.Buffer
+	args []interface{}
+	err  error
+	errs []*BuildError
+
+	// dialect is the dialect of the statement being written.
+	dialect Dialect
+
+	// pretty breaks clauses onto lines indented by indent, depth times.
+	pretty bool
+	indent string
+	depth  int
+}
+
+// setDialect sets the dialect of the statement being written and returns a
+// func restoring the one of the enclosing statement.
+func (w *sqlWriter) setDialect(d Dialect) func() {
+	outer := w.dialect
+	w.dialect = d
+	return func() { w.dialect = outer }
+}
+
+// WriteSQL writes a nested SQLizer to the buffer without finalizing its
+// placeholders.
+func (w *sqlWriter) WriteSQL(s SQLizer) {
+	if w.err != nil {
+		return
+	}
+	if raw, ok := s.(rawSQLizer); ok {
+		if w.pretty && isStatement(s) {
+			// indent the clauses of nested statements
+			w.depth++
+			defer func() { w.depth-- }()
+		}
+		raw.writeSQL(w)
+		return
+	}
+	sql, args, err := s.ToSQL()
+	w.WriteRaw(sql, args, err)
+}
+
+// WriteRaw writes an already rendered piece of SQL and its args to the buffer.
+func (w *sqlWriter) WriteRaw(sql string, args []interface{}, err error) {
+	if w.err != nil {
+		return
+	}
+	if err != nil {
+		w.err = err
+		return
+	}
+	w.WriteString(sql)
+	w.args = append(w.args, args...)
+}
+
+// Break writes the whitespace separating two clauses: a space, or a line break
+// when pretty-printing.
+func (w *sqlWriter) Break() {
+	w.WriteString(w.BreakSep())
+}
+
+// BreakSep returns the whitespace written by Break, to separate the clauses
+// of a list such as JOINs.
+func (w *sqlWriter) BreakSep() string {
+	if !w.pretty {
+		return " "
+	}
+	return "\n" + strings.Repeat(w.indent, w.depth)
+}
+
+// Continue returns the separator of the parts of a clause which are joined by
+// an operator such as " AND ". When pretty-printing, each part after the first
+// goes on an indented line of its own.
+func (w *sqlWriter) Continue(sep string) string {
+	if !w.pretty {
+		return sep
+	}
+	return "\n" + strings.Repeat(w.indent, w.depth+1) + strings.TrimLeft(sep, " ")
+}
+
+// ListSep returns the separator of the items of a list such as ", ". When
+// pretty-printing, each item after the first goes on an indented line of its
+// own.
+func (w *sqlWriter) ListSep(sep string) string {
+	if !w.pretty {
+		return sep
+	}
+	return strings.TrimRight(sep, " ") + "\n" + strings.Repeat(w.indent, w.depth+1)
+}
+
+// alignedSep is like Continue, but aligns the parts with the column the
+// writer is at rather than indenting them.
+func (w *sqlWriter) alignedSep(sep string) string {
+	if !w.pretty {
+		return sep
+	}
+	line := w.Bytes()[w.Len()-w.column():]
+	pad := make([]byte, len(line))
+	for i, c := range line {
+		if c == '\t' {
+			pad[i] = '\t'
+		} else {
+			pad[i] = ' '
+		}
+	}
+	return "\n" + string(pad) + strings.TrimLeft(sep, " ")
+}
+
+// WriteSubquery writes a parenthesized statement. When pretty-printing, the
+// statement goes on indented lines of its own.
+func (w *sqlWriter) WriteSubquery(s SQLizer) {
+	w.WriteByte('(')
+	if w.pretty {
+		// WriteSQL indents the clauses of s to match its first line
+		w.newline(w.depth + 1)
+		w.WriteSQL(s)
+		w.newline(w.depth)
+	} else {
+		w.WriteSQL(s)
+	}
+	w.WriteByte(')')
+}
+
+// column returns the number of bytes written since the last line break.
+func (w *sqlWriter) column() int {
+	return w.Len() - (bytes.LastIndexByte(w.Bytes(), '\n') + 1)
+}
+
+func (w *sqlWriter) newline(depth int) {
+	w.WriteByte('\n')
+	w.WriteString(strings.Repeat(w.indent, depth))
+}
+
+// WriteParts writes parts separated by sep, skipping the ones rendering to an
+// empty string.
+func (w *sqlWriter) WriteParts(parts []SQLizer, sep string) {
+	w.writeParts("", parts, sep)
+}
+
+// WriteClause is like WriteParts, but the error of each part is recorded as a
+// *BuildError of clause and writing carries on with the next part.
+func (w *sqlWriter) WriteClause(clause string, parts []SQLizer, sep string) {
+	w.writeParts(clause, parts, sep)
+}
+
+func (w *sqlWriter) writeParts(clause string, parts []SQLizer, sep string) {
+	n := 0
+	for i, p := range parts {
+		if w.err != nil {
+			return
+		}
+
+		m := w.mark()
+		if n > 0 {
+			w.WriteString(sep)
+		}
+		start := w.Len()
+		w.WriteSQL(p)
+		if clause != "" && w.recordPartError(m, clause, i, p) {
+			continue
+		}
+		if w.err == nil && w.Len() == start {
+			// empty part; drop the separator and any args it produced
+			w.reset(m)
+			continue
+		}
+		n++
+	}
+}
+
+// WriteConditions writes the predicates of clause ANDed together. When
+// pretty-printing, each predicate after the first goes on an indented line of
+// its own, and statements nested in them are indented one level further.
+func (w *sqlWriter) WriteConditions(clause string, parts []SQLizer) {
+	sep := w.Continue(" AND ")
+	w.depth++
+	w.WriteClause(clause, parts, sep)
+	w.depth--
+}
+
+// writerMark is a position in a sqlWriter which it can be reset to.
+type writerMark struct {
+	len, argc, errc int
+}
+
+func (w *sqlWriter) mark() writerMark {
+	return writerMark{len: w.Len(), argc: len(w.args), errc: len(w.errs)}
+}
+
+func (w *sqlWriter) reset(m writerMark) {
+	w.Truncate(m.len)
+	w.args = w.args[:m.argc]
+}
+
+// recordPartError checks whether writing the i-th part p of clause since m
+// failed. If it did, the error is recorded as a *BuildError, the writer is
+// reset to m and true is returned.
+func (w *sqlWriter) recordPartError(m writerMark, clause string, i int, p interface{}) bool {
+	if w.err == nil && len(w.errs) == m.errc {
+		return false
+	}
+	e := w.buildError(clause, i, p, w.errs[m.errc:])
+	w.errs = append(w.errs[:m.errc], e)
+	w.err = nil
+	w.reset(m)
+	return true
+}
+
+// buildError returns the error of the i-th part p of clause, given the errors
+// recorded while writing it.
+func (w *sqlWriter) buildError(clause string, i int, p interface{}, errs []*BuildError) *BuildError {
+	err := w.err
+	if err == nil {
+		err = joinBuildErrors(errs)
+	} else if len(errs) > 0 {
+		nested := append(BuildErrors(nil), errs...)
+		err = append(nested, &BuildError{Clause: clause, Index: i, Err: err})
+	}
+
+	e := &BuildError{Clause: clause, Index: i, Err: err}
+	if s, ok := p.(siter); ok {
+		e.Caller = s.site().String()
+	}
+	return e
+}
+
+// WriteArg writes a placeholder to the buffer and binds arg to it.
+func (w *sqlWriter) WriteArg(arg interface{}) {
+	if w.err != nil {
+		return
+	}
+	w.WriteByte('?')
+	w.args = append(w.args, arg)
+}
+
+// Fail records err unless an error has already been recorded.
+func (w *sqlWriter) Fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// Err returns the first error encountered while writing, which doesn't include
+// the errors collected by WriteClause.
+func (w *sqlWriter) Err() error {
+	return w.err
+}
+
+// ToSQL returns what has been written to the buffer so far.
+func (w *sqlWriter) ToSQL() (string, []interface{}, error) {
+	if w.err != nil {
+		return "", nil, w.err
+	}
+	if err := joinBuildErrors(w.errs); err != nil {
+		return "", nil, err
+	}
+	return w.String(), w.args, nil
+}
+
+// isStatement reports whether s is a builder of a complete statement.
+func isStatement(s SQLizer) bool {
+	switch s.(type) {
+	case SelectBuilder, InsertBuilder, UpdateBuilder, DeleteBuilder, MergeBuilder, BulkUpdateBuilder:
+		return true
+	}
+	return false
+}
+
+// rawToSQL renders s into a fresh sqlWriter, leaving placeholders unfinalized.
+func rawToSQL(s rawSQLizer) (string, []interface{}, error) {
+	w := &sqlWriter{}
+	s.writeSQL(w)
+	return w.ToSQL()
+}
+
+// prettyToSQL is like rawToSQL, but breaks clauses onto lines indented by
+// indent.
+func prettyToSQL(s rawSQLizer, indent string) (string, []interface{}, error) {
+	w := &sqlWriter{pretty: true, indent: indent}
+	s.writeSQL(w)
+	return w.ToSQL()
+}